@@ -0,0 +1,161 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/actions/gh-actions-cache/internal"
+	"github.com/actions/gh-actions-cache/service"
+	"github.com/actions/gh-actions-cache/types"
+	"github.com/spf13/cobra"
+)
+
+func NewCmdApply() *cobra.Command {
+	applyCommand := "apply"
+	f := types.ApplyOptions{}
+
+	var applyCmd = &cobra.Command{
+		Use:   "apply <plan>",
+		Short: "Deletes the caches of a plan written by prune --out that are unchanged since",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return fmt.Errorf(fmt.Sprintf("accepts 1 arg(s), received %d", len(args)))
+			}
+
+			err := f.Validate()
+			if err != nil {
+				return err
+			}
+
+			// This will silence the usage (help) message as they are not needed for errors beyond this point
+			cmd.SilenceUsage = true
+
+			plan, err := internal.LoadPlan(args[0])
+			if err != nil {
+				return types.HandledError{Message: fmt.Sprintf("Could not read the plan file '%s'", args[0]), InnerError: err}
+			}
+			if len(plan.Entries) == 0 {
+				fmt.Println("The plan has no cache entries to delete")
+				return nil
+			}
+			repo, err := internal.GetRepo(plan.Repo)
+			if err != nil {
+				return types.HandledError{Message: fmt.Sprintf("Invalid repo '%s' in the plan file '%s'", plan.Repo, args[0]), InnerError: err}
+			}
+
+			artifactCache, err := service.NewArtifactCache(repo, applyCommand, VERSION)
+			if err != nil {
+				return types.HandledError{Message: err.Error(), InnerError: err}
+			}
+
+			current, err := getAllCaches(cmd.Context(), artifactCache)
+			if err != nil {
+				return internal.HttpErrorHandler(err, "The given repo does not exist.")
+			}
+			caches, changes := internal.VerifyPlan(plan, current)
+			printPlanChanges(changes)
+			caches, protected := f.Protection().Split(caches)
+			if len(caches) == 0 {
+				printProtectedCaches(protected)
+				fmt.Println("None of the planned cache entries are left to delete")
+				return nil
+			}
+			if err := checkDeletionLimits(cmd.Context(), artifactCache, f.DeletionLimits, caches); err != nil {
+				return err
+			}
+			confirmed, err := confirmDeletion(f.Confirm, caches, protected)
+			if err != nil || !confirmed {
+				return err
+			}
+
+			reason := f.Reason
+			if reason == "" {
+				reason = plan.Reason
+			}
+			audit, err := newAuditRecorder(cmd.Context(), artifactCache, repo, reason)
+			if err != nil {
+				return err
+			}
+			now := time.Now()
+			path := f.Journal
+			if path == "" {
+				if path, err = internal.DefaultJournalPath(plan.Repo, now); err != nil {
+					return types.HandledError{Message: "Could not locate the journal.", InnerError: err}
+				}
+			}
+			journal, err := internal.CreateJournal(path, types.JournalPlan{Repo: plan.Repo, CreatedAt: now.UTC(), Reason: reason, Caches: caches, Verify: true})
+			if err != nil {
+				return types.HandledError{Message: fmt.Sprintf("Could not write the journal '%s'", path), InnerError: err}
+			}
+			return runJournaledDeletion(cmd.Context(), artifactCache, repo, journal, caches, f.Parallel, audit, f.Summary, f.Journal == "")
+		},
+	}
+
+	applyCmd.Flags().BoolVar(&f.Confirm, "confirm", false, "Delete the caches without asking user for confirmation.")
+	applyCmd.Flags().BoolVar(&f.Summary, "summary", false, "Append the deleted entries to the GitHub Actions job summary.")
+	applyCmd.Flags().IntVar(&f.Parallel, "parallel", 1, "Number of concurrent delete requests between 1 and 20.")
+	applyCmd.Flags().StringVar(&f.Journal, "journal", "", "Write the journal of the deletions to this file.")
+	addProtectionFlags(applyCmd, &f.ProtectionOptions)
+	addDeletionLimitFlags(applyCmd, &f.DeletionLimits)
+	applyCmd.Flags().StringVar(&f.Reason, "reason", "", "Why the caches are deleted, recorded in the audit log. Defaults to the reason of the plan.")
+	applyCmd.SetHelpTemplate(getApplyHelp())
+
+	return applyCmd
+}
+
+// printPlanChanges lists the planned caches that are skipped because they changed since the plan.
+func printPlanChanges(changes []internal.PlanChange) {
+	if len(changes) == 0 {
+		return
+	}
+	fmt.Printf("Skipping %s changed since the plan\n", internal.PrintSingularOrPlural(len(changes), "cache entry", "cache entries"))
+	for _, change := range changes {
+		fmt.Printf("- %s (%s, id %d): %s\n", change.Entry.Key, change.Entry.Ref, change.Entry.Id, change.Reason)
+	}
+	fmt.Println()
+}
+
+func getApplyHelp() string {
+	return `
+gh-actions-cache: Works with GitHub Actions Cache.
+
+USAGE:
+	gh actions-cache apply <plan> [flags]
+
+ARGUMENTS:
+	plan		JSON plan file written by gh actions-cache prune --out
+
+FLAGS:
+	--confirm				Confirm deletion without prompting
+	--summary				Append a table of the deleted entries to $GITHUB_STEP_SUMMARY when set
+	--parallel <int>			Number of concurrent delete requests (default is 1, max is 20)
+	--journal <file>			Write the journal of the deletions to this file instead of the default path, see prune --resume
+	--protect-ref <ref>			Never delete the caches of a branch or full ref, can be repeated. Added to protected-refs of the configuration
+	--protect-key-prefix <prefix>		Never delete the caches whose key starts with prefix, can be repeated. Added to protected-key-prefixes of the configuration
+	--allow-protected			Delete the planned caches even when they are protected
+	--max-entries <int>			Refuse to delete more cache entries at once unless --force is given
+	--max-size <size>			Refuse to delete more than this size at once, e.g. 2GB, unless --force is given
+	--max-usage-percent <percent>		Refuse to delete more than this percentage of the cache usage at once unless --force is given
+	--force					Delete whatever --max-entries, --max-size and --max-usage-percent and their configuration
+	--reason <string>			Why the caches are deleted, recorded with every deletion in the audit log. Defaults to the reason of the plan
+
+PLAN:
+	The plan lists the repo and the id, key, ref, version, size and last use of every cache to delete. apply lists
+	the caches of the repo again and only deletes the planned ones that still exist with the same key, ref, version
+	and size, and that were not used since the plan. The others are listed and skipped.
+
+INHERITED FLAGS
+	--help		Show help for command
+	--verbose	Log every request with its status and timing, the remaining API rate limit and the retries on stderr
+	--debug		Also log the request headers, the body of failed responses and the cause of errors, with tokens redacted. Also enabled by GH_DEBUG
+	--max-retry-wait <duration>	Longest total time to wait while retrying a rate limited or failed request (default is 1m)
+	--timeout <duration>		Abort the command if it runs longer than the given duration, e.g. 5m
+	--cache-ttl <duration>		Store listings and usage on disk and send conditional requests to revalidate them for that long, e.g. 1h (disabled by default)
+	--no-cache			Bypass the responses stored with --cache-ttl
+
+EXAMPLES:
+	$ gh actions-cache prune --older-than 30d --out plan.json
+	$ gh actions-cache apply plan.json
+	$ gh actions-cache apply plan.json --confirm --parallel 8
+`
+}
@@ -0,0 +1,153 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/actions/gh-actions-cache/internal"
+	"github.com/actions/gh-actions-cache/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/h2non/gock.v1"
+)
+
+func TestPruneWithOutWritesAPlanWithoutDeleting(t *testing.T) {
+	t.Cleanup(gock.Off)
+	planPath := filepath.Join(t.TempDir(), "plan.json")
+
+	gock.New("https://api.github.com").
+		Get("/repos/testOrg/testRepo/actions/caches").
+		MatchParam("per_page", "100").
+		Reply(200).
+		JSON(PRUNE_LISTING)
+
+	cmd := NewCmdPrune()
+	cmd.SetArgs([]string{"--repo", "testOrg/testRepo", "--older-than", "30d", "--out", planPath, "--reason", "stale"})
+	err := cmd.Execute()
+
+	require.NoError(t, err)
+	assert.True(t, gock.IsDone(), internal.PrintPendingMocks(gock.Pending()))
+	plan, err := internal.LoadPlan(planPath)
+	require.NoError(t, err)
+	assert.Equal(t, "github.com/testOrg/testRepo", plan.Repo)
+	assert.Equal(t, "stale", plan.Reason)
+	if assert.Len(t, plan.Entries, 2) {
+		entry := plan.Entries[0]
+		assert.Equal(t, int64(11), entry.Id)
+		assert.Equal(t, "Linux-node-a", entry.Key)
+		assert.Equal(t, "refs/heads/main", entry.Ref)
+		assert.Equal(t, "803758043e242677f6b8650742372d82ded436d99b2a8a09bc3b6ed77cd6aec2", entry.Version)
+		assert.Equal(t, int64(1024), entry.SizeInBytes)
+		assert.Equal(t, "2022-06-29T13:33:52.28Z", types.FormatApiTime(entry.LastAccessedAt))
+	}
+}
+
+func TestApplySkipsTheEntriesChangedSinceThePlan(t *testing.T) {
+	t.Cleanup(gock.Off)
+	useAuditLog(t)
+	planPath := filepath.Join(t.TempDir(), "plan.json")
+	journalPath := filepath.Join(t.TempDir(), "apply.jsonl")
+	summaryPath := filepath.Join(t.TempDir(), "summary.md")
+	t.Setenv(internal.STEP_SUMMARY_ENV, summaryPath)
+
+	gock.New("https://api.github.com").
+		Get("/repos/testOrg/testRepo/actions/caches").
+		MatchParam("per_page", "100").
+		Reply(200).
+		JSON(PRUNE_LISTING)
+	cmd := NewCmdPrune()
+	cmd.SetArgs([]string{"--repo", "testOrg/testRepo", "--older-than", "30d", "--out", planPath})
+	require.NoError(t, cmd.Execute())
+
+	gock.New("https://api.github.com").
+		Get("/repos/testOrg/testRepo/actions/caches").
+		MatchParam("per_page", "100").
+		Reply(200).
+		JSON(`{
+			"total_count": 2,
+			"actions_caches": [
+				{"id": 11, "ref": "refs/heads/main", "key": "Linux-node-a", "version": "803758043e242677f6b8650742372d82ded436d99b2a8a09bc3b6ed77cd6aec2", "last_accessed_at": "2022-06-29T13:33:52.280000000Z", "created_at": "2022-06-29T13:33:52.280000000Z", "size_in_bytes": 1024},
+				{"id": 12, "ref": "refs/heads/main", "key": "Linux-node-b", "version": "803758043e242677f6b8650742372d82ded436d99b2a8a09bc3b6ed77cd6aec2", "last_accessed_at": "2024-01-31T10:00:00.000000000Z", "created_at": "2022-06-29T13:33:52.280000000Z", "size_in_bytes": 2048}
+			]
+		}`)
+	gock.New("https://api.github.com").
+		Delete("/repos/testOrg/testRepo/actions/caches/11").
+		Reply(204)
+
+	mockUserLogin()
+	cmd = NewCmdApply()
+	cmd.SetArgs([]string{planPath, "--confirm", "--summary", "--journal", journalPath})
+	err := cmd.Execute()
+
+	require.NoError(t, err)
+	assert.True(t, gock.IsDone(), internal.PrintPendingMocks(gock.Pending()))
+	journal := readJournalFile(t, journalPath)
+	if assert.Len(t, journal.Plan.Caches, 1) {
+		assert.Equal(t, int64(11), journal.Plan.Caches[0].Id)
+	}
+	assert.Empty(t, journal.Remaining())
+
+	content, err := os.ReadFile(summaryPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "1 cache entry deleted, 1.00 KB reclaimed")
+	assert.Contains(t, string(content), "| Linux-node-a | refs/heads/main | 1.00 KB | 1024 |")
+	assert.NotContains(t, string(content), "Linux-node-b")
+}
+
+func TestPruneResumeOfAnApplyVerifiesThePlanAgain(t *testing.T) {
+	t.Cleanup(gock.Off)
+	useFastRetries(t, 0)
+	useAuditLog(t)
+	planPath := filepath.Join(t.TempDir(), "plan.json")
+	journalPath := filepath.Join(t.TempDir(), "apply.jsonl")
+
+	gock.New("https://api.github.com").
+		Get("/repos/testOrg/testRepo/actions/caches").
+		MatchParam("per_page", "100").
+		Times(2).
+		Reply(200).
+		JSON(PRUNE_LISTING)
+	cmd := NewCmdPrune()
+	cmd.SetArgs([]string{"--repo", "testOrg/testRepo", "--older-than", "30d", "--out", planPath})
+	require.NoError(t, cmd.Execute())
+
+	gock.New("https://api.github.com").
+		Delete("/repos/testOrg/testRepo/actions/caches/11").
+		Reply(204)
+	gock.New("https://api.github.com").
+		Delete("/repos/testOrg/testRepo/actions/caches/12").
+		Reply(403).
+		JSON(`{"message": "Resource not accessible by integration"}`)
+	mockUserLogin()
+	cmd = NewCmdApply()
+	cmd.SetArgs([]string{planPath, "--confirm", "--journal", journalPath})
+	assert.Error(t, cmd.Execute())
+	assert.True(t, gock.IsDone(), internal.PrintPendingMocks(gock.Pending()))
+	assert.True(t, readJournalFile(t, journalPath).Plan.Verify)
+
+	gock.New("https://api.github.com").
+		Get("/repos/testOrg/testRepo/actions/caches").
+		MatchParam("per_page", "100").
+		Reply(200).
+		JSON(`{
+			"total_count": 1,
+			"actions_caches": [
+				{"id": 12, "ref": "refs/heads/main", "key": "Linux-node-b", "version": "803758043e242677f6b8650742372d82ded436d99b2a8a09bc3b6ed77cd6aec2", "last_accessed_at": "2024-01-31T10:00:00.000000000Z", "created_at": "2022-06-29T13:33:52.280000000Z", "size_in_bytes": 2048}
+			]
+		}`)
+	cmd = NewCmdPrune()
+	cmd.SetArgs([]string{"--resume", journalPath, "--confirm"})
+	err := cmd.Execute()
+
+	require.NoError(t, err)
+	assert.True(t, gock.IsDone(), internal.PrintPendingMocks(gock.Pending()))
+}
+
+func TestApplyWithMissingPlan(t *testing.T) {
+	cmd := NewCmdApply()
+	cmd.SetArgs([]string{filepath.Join(t.TempDir(), "plan.json")})
+	err := cmd.Execute()
+
+	assert.ErrorContains(t, err, "Could not read the plan file")
+}
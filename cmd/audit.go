@@ -0,0 +1,227 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/actions/gh-actions-cache/internal"
+	"github.com/actions/gh-actions-cache/service"
+	"github.com/actions/gh-actions-cache/types"
+	ghRepo "github.com/cli/go-gh/pkg/repository"
+	ghTerm "github.com/cli/go-gh/pkg/term"
+	"github.com/spf13/cobra"
+)
+
+// auditLogPath is set from the audit-log setting, the default path is used when it is empty.
+var auditLogPath string
+
+func NewCmdAudit() *cobra.Command {
+	var repoFilter, keyFilter, since, until, format string
+
+	var auditCmd = &cobra.Command{
+		Use:   "audit",
+		Short: "Queries the local log of deleted caches",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 0 {
+				return fmt.Errorf(fmt.Sprintf("Invalid argument(s). Expected 0 received %d", len(args)))
+			}
+			if format != "table" && format != "json" {
+				return fmt.Errorf(fmt.Sprintf("%s is not a valid value for format flag. Allowed values: table/json", format))
+			}
+
+			filter := types.AuditFilter{Repo: repoFilter, Key: keyFilter}
+			var err error
+			if filter.Since, err = parseAuditTime(since, false); err != nil {
+				return fmt.Errorf(fmt.Sprintf("%s is not a valid value for since flag. Expected a date like 2024-01-31, a time like 2024-01-31T10:00:00Z or a duration like 72h", since))
+			}
+			if filter.Until, err = parseAuditTime(until, true); err != nil {
+				return fmt.Errorf(fmt.Sprintf("%s is not a valid value for until flag. Expected a date like 2024-01-31, a time like 2024-01-31T10:00:00Z or a duration like 72h", until))
+			}
+
+			// This will silence the usage (help) message as they are not needed for errors beyond this point
+			cmd.SilenceUsage = true
+
+			path, err := auditLogFile()
+			if err != nil {
+				return types.HandledError{Message: "Could not locate the audit log.", InnerError: err}
+			}
+			file, err := os.Open(path)
+			if errors.Is(err, fs.ErrNotExist) {
+				if format == "json" {
+					fmt.Fprintln(cmd.OutOrStdout(), "[]")
+				} else if ghTerm.FromEnv().IsTerminalOutput() {
+					fmt.Printf("No deletions are recorded in %s\n", path)
+				}
+				return nil
+			}
+			if err != nil {
+				return types.HandledError{Message: fmt.Sprintf("Could not read the audit log '%s'", path), InnerError: err}
+			}
+			defer file.Close()
+
+			records, err := internal.ReadAuditRecords(file, filter)
+			if err != nil {
+				return types.HandledError{Message: fmt.Sprintf("Could not read the audit log '%s'", path), InnerError: err}
+			}
+
+			if format == "json" {
+				if records == nil {
+					records = []types.AuditRecord{}
+				}
+				encoder := json.NewEncoder(cmd.OutOrStdout())
+				encoder.SetIndent("", "  ")
+				if err := encoder.Encode(records); err != nil {
+					return types.HandledError{Message: "Could not write the audit records.", InnerError: err}
+				}
+				return nil
+			}
+			if len(records) == 0 {
+				if ghTerm.FromEnv().IsTerminalOutput() {
+					fmt.Printf("No recorded deletions match the given filters\n")
+				}
+				return nil
+			}
+			internal.PrettyPrintAuditRecords(records)
+			return nil
+		},
+	}
+
+	auditCmd.Flags().StringVarP(&repoFilter, "repo", "R", "", "Only show the deletions in a [HOST/]OWNER/REPO repository.")
+	_ = auditCmd.Flags().SetAnnotation("repo", NO_CONFIG_DEFAULT, []string{"true"})
+	auditCmd.Flags().StringVar(&keyFilter, "key", "", "Only show the deletions of keys starting with it.")
+	auditCmd.Flags().StringVar(&since, "since", "", "Only show the deletions since a date, a time or a duration ago.")
+	auditCmd.Flags().StringVar(&until, "until", "", "Only show the deletions until a date, a time or a duration ago.")
+	auditCmd.Flags().StringVar(&format, "format", "table", "Output format (table/json)")
+	auditCmd.SetHelpTemplate(getAuditHelp())
+
+	return auditCmd
+}
+
+// parseAuditTime reads a date, an RFC 3339 time or a duration before now. A date as the end of a range includes
+// the whole day.
+func parseAuditTime(value string, endOfDay bool) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if duration, err := time.ParseDuration(value); err == nil {
+		return time.Now().Add(-duration), nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	day, err := time.ParseInLocation("2006-01-02", value, time.Local)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if endOfDay {
+		return day.AddDate(0, 0, 1), nil
+	}
+	return day, nil
+}
+
+func auditLogFile() (string, error) {
+	if auditLogPath != "" {
+		return auditLogPath, nil
+	}
+	return internal.DefaultAuditLogPath()
+}
+
+// auditRecorder appends an audit record for every deleted cache as soon as its deletion finished, so that the
+// deletions done before the process is stopped or killed are recorded. A failure to record is reported but does
+// not fail the command since the caches are already deleted.
+type auditRecorder struct {
+	path   string
+	record types.AuditRecord
+	failed bool
+}
+
+// newAuditRecorder checks that the audit log can be appended to, so that no cache is deleted without being
+// recorded, then looks up the login of the token owner, which falls back to GITHUB_ACTOR for tokens that cannot
+// read it. It is called once the caches to delete are known.
+func newAuditRecorder(ctx context.Context, artifactCache service.ArtifactCacheService, repo ghRepo.Repository, reason string) (*auditRecorder, error) {
+	path, err := auditLogFile()
+	if err != nil {
+		return nil, types.HandledError{Message: "Could not locate the audit log, no cache was deleted.", InnerError: err}
+	}
+	if err := internal.CheckAuditLog(path); err != nil {
+		return nil, types.HandledError{Message: fmt.Sprintf("Could not open the audit log '%s', no cache was deleted.", path), InnerError: err}
+	}
+
+	login, err := artifactCache.GetUserLogin(ctx)
+	if err != nil || login == "" {
+		login = os.Getenv("GITHUB_ACTOR")
+	}
+	return &auditRecorder{path: path, record: types.AuditRecord{
+		Host:    repo.Host(),
+		Repo:    fmt.Sprintf("%s/%s", repo.Owner(), repo.Name()),
+		User:    login,
+		Command: service.Redact(strings.Join(os.Args, " ")),
+		Reason:  reason,
+	}}, nil
+}
+
+// Finished records the caches deleted by a job. It is meant for service.Deleter.Finished, which is never called
+// concurrently.
+func (a *auditRecorder) Finished(result service.DeletionResult) {
+	a.Record(result.Deleted)
+}
+
+func (a *auditRecorder) Record(caches []types.Cache) {
+	if len(caches) == 0 || a.failed {
+		return
+	}
+	now := time.Now().UTC()
+	records := make([]types.AuditRecord, 0, len(caches))
+	for _, cache := range caches {
+		record := a.record
+		record.Time = now
+		record.CacheId = cache.Id
+		record.Key = cache.Key
+		record.Ref = cache.Ref.Raw
+		record.Version = cache.Version
+		record.SizeInBytes = cache.SizeInBytes
+		records = append(records, record)
+	}
+	if err := internal.AppendAuditRecords(a.path, records); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: could not write the audit log '%s': %s\n", a.path, err)
+		a.failed = true
+	}
+}
+
+func getAuditHelp() string {
+	return `
+gh-actions-cache: Works with GitHub Actions Cache.
+
+USAGE:
+	gh actions-cache audit [flags]
+
+ARGUMENTS:
+	No Arguments
+
+FLAGS:
+	-R, --repo <[HOST/]owner/repo>		Only show the deletions in this repository
+	--key <string>				Only show the deletions of keys starting with it
+	--since <date|time|duration>		Only show the deletions since a date like 2024-01-31, a time like 2024-01-31T10:00:00Z or a duration ago like 72h
+	--until <date|time|duration>		Only show the deletions until a date (included), a time or a duration ago
+	--format <string>			Output format (table/json)
+
+FILES:
+	delete, interactive, prune and apply append a JSON Lines record for every cache as soon as it is deleted, to the
+	audit-log setting or to ~/.local/state/gh-actions-cache/audit.jsonl ($XDG_STATE_HOME when set). A record holds
+	the time, host, repo, user login, cache id, key, ref, version and size, the command line and the --reason.
+	They fail without deleting anything when the audit log cannot be opened.
+
+INHERITED FLAGS
+	--help		Show help for command
+
+EXAMPLES:
+	$ gh actions-cache audit --since 168h
+	$ gh actions-cache audit -R octo-org/octo-repo --key Linux-node- --format json
+`
+}
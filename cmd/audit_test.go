@@ -0,0 +1,231 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/actions/gh-actions-cache/internal"
+	"github.com/actions/gh-actions-cache/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/h2non/gock.v1"
+)
+
+func useAuditLog(t *testing.T) string {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	previous := auditLogPath
+	t.Cleanup(func() { auditLogPath = previous })
+	auditLogPath = path
+	return path
+}
+
+// mockUserLogin replies to the lookup of the login of the token owner, done once by every command that deletes.
+func mockUserLogin() {
+	gock.New("https://api.github.com").
+		Get("/user").
+		Reply(200).
+		JSON(`{"login": "monalisa"}`)
+}
+
+func TestDeleteRecordsEveryDeletionInTheAuditLog(t *testing.T) {
+	t.Cleanup(gock.Off)
+	path := useAuditLog(t)
+
+	gock.New("https://api.github.com").
+		Delete("/repos/testOrg/testRepo/actions/caches").
+		MatchParam("key", "2022-06-29T13:33:49").
+		Reply(200).
+		JSON(PROTECTED_LISTING)
+
+	mockUserLogin()
+	cmd := NewCmdDelete()
+	cmd.SetArgs([]string{"--repo", "testOrg/testRepo", "2022-06-29T13:33:49", "--confirm", "--reason", "stale toolchain"})
+	err := cmd.Execute()
+	require.NoError(t, err)
+	assert.True(t, gock.IsDone(), internal.PrintPendingMocks(gock.Pending()))
+
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	defer file.Close()
+	records, err := internal.ReadAuditRecords(file, types.AuditFilter{})
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	record := records[1]
+	assert.Equal(t, "github.com", record.Host)
+	assert.Equal(t, "testOrg/testRepo", record.Repo)
+	assert.Equal(t, "monalisa", record.User)
+	assert.Equal(t, int64(1294), record.CacheId)
+	assert.Equal(t, "2022-06-29T13:33:49", record.Key)
+	assert.Equal(t, "refs/pull/2/merge", record.Ref)
+	assert.Equal(t, int64(29747), record.SizeInBytes)
+	assert.Equal(t, "stale toolchain", record.Reason)
+	assert.WithinDuration(t, time.Now(), record.Time, time.Minute)
+}
+
+func TestDeleteFailsWithoutDeletingWhenTheAuditLogCannotBeOpened(t *testing.T) {
+	t.Cleanup(gock.Off)
+	notADirectory := filepath.Join(t.TempDir(), "file")
+	require.NoError(t, os.WriteFile(notADirectory, nil, 0o600))
+	previous := auditLogPath
+	t.Cleanup(func() { auditLogPath = previous })
+	auditLogPath = filepath.Join(notADirectory, "audit.jsonl")
+
+	cmd := NewCmdDelete()
+	cmd.SetArgs([]string{"--repo", "testOrg/testRepo", "2022-06-29T13:33:49", "--confirm"})
+	err := cmd.Execute()
+
+	assert.ErrorContains(t, err, "Could not open the audit log")
+	assert.Equal(t, types.EXIT_ERROR, types.ExitCode(err))
+	assert.True(t, gock.IsDone(), internal.PrintPendingMocks(gock.Pending()))
+}
+
+func TestDeleteByIdRecordsTheDetailsOfTheCache(t *testing.T) {
+	t.Cleanup(gock.Off)
+	path := useAuditLog(t)
+
+	gock.New("https://api.github.com").
+		Get("/repos/testOrg/testRepo/actions/caches").
+		MatchParam("per_page", "100").
+		Reply(200).
+		JSON(PROTECTED_LISTING)
+
+	gock.New("https://api.github.com").
+		Delete("/repos/testOrg/testRepo/actions/caches/1294").
+		Reply(204)
+
+	mockUserLogin()
+	cmd := NewCmdDelete()
+	cmd.SetIn(strings.NewReader("1294\n"))
+	cmd.SetArgs([]string{"--repo", "testOrg/testRepo", "--from-file", "-", "--confirm"})
+	err := cmd.Execute()
+	require.NoError(t, err)
+	assert.True(t, gock.IsDone(), internal.PrintPendingMocks(gock.Pending()))
+
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	defer file.Close()
+	records, err := internal.ReadAuditRecords(file, types.AuditFilter{})
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	assert.Equal(t, int64(1294), records[0].CacheId)
+	assert.Equal(t, "2022-06-29T13:33:49", records[0].Key)
+	assert.Equal(t, "refs/pull/2/merge", records[0].Ref)
+	assert.Equal(t, "803758043e242677f6b8650742372d82ded436d99b2a8a09bc3b6ed77cd6aec2", records[0].Version)
+	assert.Equal(t, int64(29747), records[0].SizeInBytes)
+}
+
+func TestAuditFiltersRecordsAsJSON(t *testing.T) {
+	path := useAuditLog(t)
+	require.NoError(t, internal.AppendAuditRecords(path, []types.AuditRecord{
+		{Time: time.Date(2024, 1, 30, 10, 0, 0, 0, time.UTC), Host: "github.com", Repo: "testOrg/testRepo", CacheId: 1, Key: "Linux-node-a"},
+		{Time: time.Date(2024, 1, 31, 10, 0, 0, 0, time.UTC), Host: "github.com", Repo: "testOrg/testRepo", CacheId: 2, Key: "Linux-node-b"},
+		{Time: time.Date(2024, 1, 31, 11, 0, 0, 0, time.UTC), Host: "github.com", Repo: "testOrg/otherRepo", CacheId: 3, Key: "Linux-node-c"},
+		{Time: time.Date(2024, 1, 31, 12, 0, 0, 0, time.UTC), Host: "github.com", Repo: "testOrg/testRepo", CacheId: 4, Key: "Windows-node-d"},
+	}))
+
+	var out bytes.Buffer
+	cmd := NewCmdAudit()
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{"--repo", "testorg/testrepo", "--key", "Linux-", "--since", "2024-01-31T00:00:00Z", "--format", "json"})
+	err := cmd.Execute()
+	require.NoError(t, err)
+
+	var records []types.AuditRecord
+	require.NoError(t, json.Unmarshal(out.Bytes(), &records))
+	require.Len(t, records, 1)
+	assert.Equal(t, int64(2), records[0].CacheId)
+}
+
+func TestAuditWithoutLog(t *testing.T) {
+	useAuditLog(t)
+
+	var out bytes.Buffer
+	cmd := NewCmdAudit()
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{"--format", "json"})
+	err := cmd.Execute()
+
+	require.NoError(t, err)
+	assert.Equal(t, "[]\n", out.String())
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) {
+	return 0, os.ErrClosed
+}
+
+func TestAuditExitsAsInternalErrorWhenTheOutputFails(t *testing.T) {
+	path := useAuditLog(t)
+	require.NoError(t, internal.AppendAuditRecords(path, []types.AuditRecord{
+		{Time: time.Date(2024, 1, 31, 10, 0, 0, 0, time.UTC), Host: "github.com", Repo: "testOrg/testRepo", CacheId: 1, Key: "Linux-node-a"},
+	}))
+
+	cmd := NewCmdAudit()
+	cmd.SetOut(failingWriter{})
+	cmd.SetArgs([]string{"--format", "json"})
+	err := cmd.Execute()
+
+	assert.ErrorContains(t, err, "Could not write the audit records.")
+	assert.Equal(t, types.EXIT_ERROR, types.ExitCode(err))
+}
+
+func TestAuditWithInvalidSince(t *testing.T) {
+	cmd := NewCmdAudit()
+	cmd.SetArgs([]string{"--since", "last week"})
+	err := cmd.Execute()
+
+	assert.ErrorContains(t, err, "last week is not a valid value for since flag")
+}
+
+func TestParseAuditTime_IncludesTheWholeLastDay(t *testing.T) {
+	until, err := parseAuditTime("2024-01-31", true)
+	require.NoError(t, err)
+
+	assert.Equal(t, time.Date(2024, 2, 1, 0, 0, 0, 0, time.Local), until)
+}
+
+func TestPruneRecordsEachDeletionBeforeTheNextOne(t *testing.T) {
+	t.Cleanup(gock.Off)
+	path := useAuditLog(t)
+
+	gock.New("https://api.github.com").
+		Get("/repos/testOrg/testRepo/actions/caches").
+		MatchParam("per_page", "100").
+		Reply(200).
+		JSON(PRUNE_LISTING)
+	gock.New("https://api.github.com").
+		Delete("/repos/testOrg/testRepo/actions/caches/11").
+		Reply(204)
+
+	var recordedBefore []types.AuditRecord
+	gock.New("https://api.github.com").
+		Delete("/repos/testOrg/testRepo/actions/caches/12").
+		Reply(204).
+		Map(func(resp *http.Response) *http.Response {
+			file, err := os.Open(path)
+			if err == nil {
+				recordedBefore, _ = internal.ReadAuditRecords(file, types.AuditFilter{})
+				file.Close()
+			}
+			return resp
+		})
+
+	mockUserLogin()
+	cmd := NewCmdPrune()
+	cmd.SetArgs([]string{"--repo", "testOrg/testRepo", "--older-than", "30d", "--confirm", "--journal", filepath.Join(t.TempDir(), "prune.jsonl")})
+	err := cmd.Execute()
+
+	require.NoError(t, err)
+	assert.True(t, gock.IsDone(), internal.PrintPendingMocks(gock.Pending()))
+	if assert.Len(t, recordedBefore, 1) {
+		assert.Equal(t, int64(11), recordedBefore[0].CacheId)
+		assert.Equal(t, "Linux-node-a", recordedBefore[0].Key)
+	}
+}
@@ -0,0 +1,257 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/actions/gh-actions-cache/internal"
+	"github.com/actions/gh-actions-cache/types"
+	"github.com/spf13/cobra"
+)
+
+// NO_CONFIG_DEFAULT annotates the flags whose default is not replaced by the setting of the same name.
+const NO_CONFIG_DEFAULT = "gh-actions-cache/no-config-default"
+
+func NewCmdConfig() *cobra.Command {
+	var configCmd = &cobra.Command{
+		Use:   "config <command>",
+		Short: "Reads and writes the configuration defaults",
+	}
+
+	configCmd.AddCommand(newCmdConfigGet())
+	configCmd.AddCommand(newCmdConfigSet())
+	configCmd.AddCommand(newCmdConfigList())
+	configCmd.SetHelpTemplate(getConfigHelp())
+
+	return configCmd
+}
+
+func newCmdConfigGet() *cobra.Command {
+	var getCmd = &cobra.Command{
+		Use:   "get <key>",
+		Short: "Prints the effective value of a setting",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return fmt.Errorf(fmt.Sprintf("accepts 1 arg(s), received %d", len(args)))
+			}
+			key, err := types.FindConfigKey(args[0])
+			if err != nil {
+				return err
+			}
+
+			cmd.SilenceUsage = true
+
+			config, err := loadConfig()
+			if err != nil {
+				return err
+			}
+			value, _ := config.Get(key)
+			fmt.Fprintln(cmd.OutOrStdout(), value)
+			return nil
+		},
+	}
+
+	getCmd.SetHelpTemplate(getConfigHelp())
+
+	return getCmd
+}
+
+func newCmdConfigSet() *cobra.Command {
+	var local bool
+
+	var setCmd = &cobra.Command{
+		Use:   "set <key> <value>",
+		Short: "Writes a setting to the user or repository configuration file",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 2 {
+				return fmt.Errorf(fmt.Sprintf("accepts 2 arg(s), received %d", len(args)))
+			}
+			key, err := types.FindConfigKey(args[0])
+			if err != nil {
+				return err
+			}
+			if local && key.UserOnly {
+				return fmt.Errorf("%s can only be set in the user configuration file or the environment", key.Name)
+			}
+
+			cmd.SilenceUsage = true
+
+			path, err := configFilePath(local)
+			if err != nil {
+				return types.HandledError{Message: "Could not locate the configuration file.", InnerError: err}
+			}
+			config, err := internal.ReadConfigFile(path)
+			if err != nil {
+				return types.HandledError{Message: err.Error(), InnerError: err, Kind: types.ErrorKindValidation}
+			}
+			if err := key.Set(&config, args[1]); err != nil {
+				return types.HandledError{Message: err.Error(), InnerError: err, Kind: types.ErrorKindValidation}
+			}
+			if err := internal.WriteConfigFile(path, config); err != nil {
+				return types.HandledError{Message: fmt.Sprintf("Could not write the configuration file '%s'", path), InnerError: err}
+			}
+			return nil
+		},
+	}
+
+	setCmd.Flags().BoolVar(&local, "local", false, "Write to .github/actions-cache.yml of the current repository")
+	setCmd.SetHelpTemplate(getConfigHelp())
+
+	return setCmd
+}
+
+func newCmdConfigList() *cobra.Command {
+	var showOrigin bool
+
+	var listCmd = &cobra.Command{
+		Use:   "list",
+		Short: "Prints the effective value of every setting",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 0 {
+				return fmt.Errorf(fmt.Sprintf("Invalid argument(s). Expected 0 received %d", len(args)))
+			}
+
+			cmd.SilenceUsage = true
+
+			config, err := loadConfig()
+			if err != nil {
+				return err
+			}
+			for _, key := range types.CONFIG_KEYS {
+				value, origin := config.Get(key)
+				if value == "" {
+					continue
+				}
+				if showOrigin {
+					fmt.Fprintf(cmd.OutOrStdout(), "%s\t", origin)
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "%s=%s\n", key.Name, value)
+			}
+			return nil
+		},
+	}
+
+	listCmd.Flags().BoolVar(&showOrigin, "show-origin", false, "Print whether each setting comes from the user file, the repository file or the environment")
+	listCmd.SetHelpTemplate(getConfigHelp())
+
+	return listCmd
+}
+
+// loadConfig reads the configuration layers for the current directory.
+func loadConfig() (internal.LayeredConfig, error) {
+	dir, err := os.Getwd()
+	if err != nil {
+		return internal.LayeredConfig{}, types.HandledError{Message: "Could not locate the configuration file.", InnerError: err}
+	}
+	config, err := internal.LoadConfig(dir, os.Getenv)
+	if err != nil {
+		return config, types.HandledError{Message: err.Error(), InnerError: err, Kind: types.ErrorKindValidation}
+	}
+	return config, nil
+}
+
+func configFilePath(local bool) (string, error) {
+	if !local {
+		return internal.UserConfigPath()
+	}
+	dir, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+	return internal.RepoConfigPath(dir), nil
+}
+
+// applyConfig replaces the defaults of the flags left unset on cmd with the configured values, adds the cumulative
+// ones such as the protected refs to the values of the flags, and configures the tables.
+func applyConfig(cmd *cobra.Command, config internal.LayeredConfig) error {
+	for _, key := range types.CONFIG_KEYS {
+		value, origin := config.Get(key)
+		if value == "" || !key.AppliesTo(cmd.Name()) {
+			continue
+		}
+		flag := cmd.Flags().Lookup(key.Flag)
+		if flag == nil || (flag.Changed && !key.Cumulative) || flag.Annotations[NO_CONFIG_DEFAULT] != nil {
+			continue
+		}
+		if err := flag.Value.Set(value); err != nil {
+			return fmt.Errorf("invalid %s from the %s configuration: %w", key.Name, origin, err)
+		}
+	}
+
+	if columns, _ := config.Get(findConfigKey("columns")); columns != "" {
+		internal.Display.Columns = types.SplitConfigList(columns)
+	}
+	if timeFormat, _ := config.Get(findConfigKey("time-format")); timeFormat != "" {
+		internal.Display.TimeFormat = timeFormat
+	}
+	if path, _ := config.Get(findConfigKey("audit-log")); path != "" {
+		auditLogPath = path
+	}
+	return nil
+}
+
+// isConfigCommand tells whether cmd is one of the config subcommands, which read the configuration themselves.
+func isConfigCommand(cmd *cobra.Command) bool {
+	for c := cmd; c != nil; c = c.Parent() {
+		if c.Name() == "config" {
+			return true
+		}
+	}
+	return false
+}
+
+func findConfigKey(name string) types.ConfigKey {
+	key, _ := types.FindConfigKey(name)
+	return key
+}
+
+func getConfigHelp() string {
+	return `
+gh-actions-cache: Works with GitHub Actions Cache.
+
+USAGE:
+	gh actions-cache config get <key>
+	gh actions-cache config set <key> <value> [flags]
+	gh actions-cache config list [flags]
+
+ARGUMENTS:
+	key		name of the setting, see KEYS
+	value		new value of the setting, lists are comma separated and an empty value unsets the setting
+
+FLAGS:
+	--local			With set, write to .github/actions-cache.yml of the current repository instead of the user file
+	--show-origin		With list, print whether each setting comes from the user file, the repository file or the environment
+
+KEYS:
+	repo			Repository used without --repo, in the [HOST/]OWNER/REPO format
+	format			Output format of list (table/csv/tsv/json)
+	columns			Columns of the tables, among key,size,ref,last-used,created-at,id,version
+	time-format		How times are shown in the tables (relative/absolute)
+	sort			Sort order of list (last-used/size/created-at)
+	order			Order of list (asc/desc)
+	limit			Number of entries listed without --all (1-100)
+	cache-ttl		How long listings are stored on disk and revalidated, e.g. 1h
+	protected-refs		Refs whose caches are never deleted
+	protected-key-prefixes	Key prefixes of caches that are never deleted
+	max-delete-entries	Most cache entries deleted at once without --force
+	max-delete-size		Largest size deleted at once without --force, e.g. 2GB
+	max-delete-usage-percent	Largest percentage of the cache usage deleted at once without --force
+	audit-log		JSON Lines file every deletion is recorded to, only read from the user file and the environment
+
+FILES:
+	Settings are read from ~/.config/gh-actions-cache/config.yml ($XDG_CONFIG_HOME when set), then from
+	.github/actions-cache.yml of the current repository, then from GH_ACTIONS_CACHE_<KEY> variables such as
+	GH_ACTIONS_CACHE_TIME_FORMAT (GH_REPO also sets the repo). Flags take precedence over all of them.
+	protected-refs and protected-key-prefixes add up across the files, the variables and the flags instead.
+	max-delete-entries, max-delete-size and max-delete-usage-percent take their lowest value instead, so that a
+	repository file can lower the limits of the user file but not raise them.
+
+INHERITED FLAGS
+	--help		Show help for command
+
+EXAMPLES:
+	$ gh actions-cache config set repo octo-org/octo-repo
+	$ gh actions-cache config set columns key,size,last-used --local
+	$ gh actions-cache config list --show-origin
+`
+}
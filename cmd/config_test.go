@@ -0,0 +1,150 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/actions/gh-actions-cache/internal"
+	"github.com/actions/gh-actions-cache/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigSetGetAndList(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", home)
+	t.Setenv("GH_ACTIONS_CACHE_ORDER", "asc")
+
+	for _, args := range [][]string{{"set", "repo", "testOrg/testRepo"}, {"set", "columns", "key,size"}} {
+		cmd := NewCmdConfig()
+		cmd.SetArgs(args)
+		require.NoError(t, cmd.Execute())
+	}
+
+	var out bytes.Buffer
+	cmd := NewCmdConfig()
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{"get", "repo"})
+	require.NoError(t, cmd.Execute())
+	assert.Equal(t, "testOrg/testRepo\n", out.String())
+
+	out.Reset()
+	cmd = NewCmdConfig()
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{"list", "--show-origin"})
+	require.NoError(t, cmd.Execute())
+	assert.Equal(t, "user\trepo=testOrg/testRepo\nuser\tcolumns=key,size\nenv\torder=asc\n", out.String())
+
+	config, err := internal.ReadConfigFile(filepath.Join(home, "gh-actions-cache", "config.yml"))
+	require.NoError(t, err)
+	assert.Equal(t, types.Config{Repo: "testOrg/testRepo", Columns: []string{"key", "size"}}, config)
+}
+
+func TestConfigSetWithInvalidValue(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	cmd := NewCmdConfig()
+	cmd.SetArgs([]string{"set", "limit", "500"})
+	err := cmd.Execute()
+
+	assert.ErrorContains(t, err, "500 is not a valid value for limit. Allowed values: 1-100")
+	assert.Equal(t, types.EXIT_VALIDATION, types.ExitCode(err))
+}
+
+func TestConfigSetLocalRejectsUserOnlySettings(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	cmd := NewCmdConfig()
+	cmd.SetArgs([]string{"set", "audit-log", "/dev/null", "--local"})
+	err := cmd.Execute()
+
+	assert.ErrorContains(t, err, "audit-log can only be set in the user configuration file or the environment")
+}
+
+func TestApplyConfig_KeepsFlagsSetOnTheCommandLine(t *testing.T) {
+	display := internal.Display
+	t.Cleanup(func() { internal.Display = display })
+	config := internal.LayeredConfig{Layers: []internal.ConfigLayer{
+		{Origin: internal.CONFIG_ORIGIN_USER, Config: types.Config{Repo: "user/repo", Limit: 50, Sort: "size", TimeFormat: "absolute"}},
+		{Origin: internal.CONFIG_ORIGIN_REPO, Config: types.Config{Sort: "created-at", Columns: []string{"key", "id"}}},
+	}}
+
+	listCmd := NewCmdList()
+	require.NoError(t, listCmd.ParseFlags([]string{"--limit", "10"}))
+	require.NoError(t, applyConfig(listCmd, config))
+
+	assert.Equal(t, "user/repo", listCmd.Flags().Lookup("repo").Value.String())
+	assert.Equal(t, "10", listCmd.Flags().Lookup("limit").Value.String())
+	assert.Equal(t, "created-at", listCmd.Flags().Lookup("sort").Value.String())
+	assert.Equal(t, []string{"key", "id"}, internal.Display.Columns)
+	assert.Equal(t, "absolute", internal.Display.TimeFormat)
+
+	reportCmd := NewCmdReport()
+	require.NoError(t, reportCmd.ParseFlags(nil))
+	require.NoError(t, applyConfig(reportCmd, internal.LayeredConfig{Layers: []internal.ConfigLayer{{Config: types.Config{Format: "csv"}}}}))
+	assert.Equal(t, "markdown", reportCmd.Flags().Lookup("format").Value.String())
+}
+
+func TestApplyConfig_AddsProtectedRefsToTheFlags(t *testing.T) {
+	config := internal.LayeredConfig{Layers: []internal.ConfigLayer{
+		{Origin: internal.CONFIG_ORIGIN_REPO, Config: types.Config{ProtectedRefs: []string{"main", "release"}}},
+	}}
+
+	deleteCmd := NewCmdDelete()
+	require.NoError(t, deleteCmd.ParseFlags([]string{"--protect-ref", "develop"}))
+	require.NoError(t, applyConfig(deleteCmd, config))
+
+	refs, err := deleteCmd.Flags().GetStringSlice("protect-ref")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"develop", "main", "release"}, refs)
+}
+
+// useConfigFiles writes the user configuration file and the file of a repository checked out in the working
+// directory for the duration of the test.
+func useConfigFiles(t *testing.T, user string, repo string) {
+	home := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", home)
+	require.NoError(t, os.MkdirAll(filepath.Join(home, "gh-actions-cache"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(home, "gh-actions-cache", "config.yml"), []byte(user), 0o644))
+
+	checkout := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(checkout, ".git"), 0o755))
+	require.NoError(t, os.MkdirAll(filepath.Join(checkout, ".github"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(checkout, ".github", "actions-cache.yml"), []byte(repo), 0o644))
+
+	dir, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(checkout))
+	t.Cleanup(func() { _ = os.Chdir(dir) })
+}
+
+func TestApplyConfig_KeepsTheProtectionsOfTheUserFileWithARepositoryFile(t *testing.T) {
+	useConfigFiles(t, "protected-refs: [main]\nprotected-key-prefixes: [setup-]\n", "protected-refs: [release]\n")
+	config, err := loadConfig()
+	require.NoError(t, err)
+
+	deleteCmd := NewCmdDelete()
+	require.NoError(t, deleteCmd.ParseFlags([]string{"--protect-ref", "develop"}))
+	require.NoError(t, applyConfig(deleteCmd, config))
+
+	refs, err := deleteCmd.Flags().GetStringSlice("protect-ref")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"develop", "main", "release"}, refs)
+	prefixes, err := deleteCmd.Flags().GetStringSlice("protect-key-prefix")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"setup-"}, prefixes)
+}
+
+func TestApplyConfig_KeepsTheLimitsOfTheUserFileWithARepositoryFile(t *testing.T) {
+	useConfigFiles(t, "max-delete-entries: 10\n", "max-delete-entries: 100000\n")
+	config, err := loadConfig()
+	require.NoError(t, err)
+
+	deleteCmd := NewCmdDelete()
+	require.NoError(t, deleteCmd.ParseFlags(nil))
+	require.NoError(t, applyConfig(deleteCmd, config))
+
+	assert.Equal(t, "10", deleteCmd.Flags().Lookup("max-entries").Value.String())
+}
@@ -1,38 +1,73 @@
 package cmd
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
 	"net/url"
+	"os"
 	"strings"
 
 	"github.com/AlecAivazis/survey/v2"
+	"github.com/AlecAivazis/survey/v2/terminal"
 	"github.com/actions/gh-actions-cache/internal"
 	"github.com/actions/gh-actions-cache/service"
 	"github.com/actions/gh-actions-cache/types"
+	ghRepo "github.com/cli/go-gh/pkg/repository"
+	ghTerm "github.com/cli/go-gh/pkg/term"
 	"github.com/spf13/cobra"
 )
 
 var choice string = ""
 
+var askMultiSelect = func(prompt *survey.MultiSelect, response *[]int) error {
+	return survey.AskOne(prompt, response)
+}
+
+// promptError reports a failed prompt, which exits as cancelled when the user pressed Ctrl-C in it.
+func promptError(err error) types.HandledError {
+	handledError := types.HandledError{Message: "Error occurred while taking input from user while trying to delete cache.", InnerError: err}
+	if errors.Is(err, terminal.InterruptErr) {
+		handledError.Kind = types.ErrorKindCancelled
+	}
+	return handledError
+}
+
 func NewCmdDelete() *cobra.Command {
 	deleteCommand := "delete"
 	f := types.DeleteOptions{}
 
 	var deleteCmd = &cobra.Command{
-		Use:   "delete <key>",
+		Use:   "delete <key>...",
 		Short: "Delete cache by key",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			if len(args) != 1 {
-				return fmt.Errorf(fmt.Sprintf("accepts 1 arg(s), received %d", len(args)))
+			if len(args) == 0 && f.FromFile == "" {
+				return fmt.Errorf("accepts at least 1 arg(s) or --from-file, received 0")
 			}
 
-			f.Key = args[0]
+			targets, err := readDeleteTargets(args, f.FromFile, cmd.InOrStdin())
+			if err != nil {
+				return err
+			}
+			if len(targets) == 0 {
+				return fmt.Errorf("no cache keys or ids found in '%s'", f.FromFile)
+			}
 
 			repo, err := internal.GetRepo(f.Repo)
 			if err != nil {
 				return err
 			}
 
+			if f.Select && f.Confirm {
+				return fmt.Errorf("select and confirm flags cannot be used together")
+			}
+
+			err = f.Validate()
+			if err != nil {
+				return err
+			}
+
 			// This will silence the usage (help) message as they are not needed for errors beyond this point
 			cmd.SilenceUsage = true
 
@@ -41,45 +76,108 @@ func NewCmdDelete() *cobra.Command {
 				return types.HandledError{Message: err.Error(), InnerError: err}
 			}
 
-			queryParams := url.Values{}
-			f.GenerateBaseQueryParams(queryParams)
-
-			if !f.Confirm {
-				matchedCaches, err := getCacheListWithExactMatch(f, artifactCache)
+			protection := f.Protection()
+			byId := !protection.IsEmpty() || f.DeletionLimits.Enabled()
+			if !f.Confirm || byId {
+				matchedTargets, matchedCaches, protectedCaches, err := resolveDeleteTargets(cmd.Context(), f, artifactCache, targets, protection, byId)
 				if err != nil {
 					return internal.HttpErrorHandler(err, "The given repo does not exist.")
 				}
 				matchedCachesLen := len(matchedCaches)
 				if matchedCachesLen == 0 {
-					return fmt.Errorf(fmt.Sprintf("Cache with input key '%s' does not exist\n", f.Key))
+					if len(protectedCaches) > 0 {
+						printProtectedCaches(protectedCaches)
+						return types.HandledError{Message: "All the matched cache entries are protected. Use --allow-protected to delete them", Kind: types.ErrorKindValidation}
+					}
+					if len(targets) == 1 && !targets[0].IsId() {
+						return types.HandledError{Message: fmt.Sprintf("Cache with input key '%s' does not exist", targets[0].Key), Kind: types.ErrorKindNotFound}
+					}
+					return types.HandledError{Message: "None of the given cache keys or ids exist", Kind: types.ErrorKindNotFound}
 				}
-				fmt.Printf("You're going to delete %s", internal.PrintSingularOrPlural(matchedCachesLen, "cache entry\n\n", "cache entries\n\n"))
-				internal.PrettyPrintTrimmedCacheList(matchedCaches)
+				targets = matchedTargets
+
+				if f.Select {
+					printProtectedCaches(protectedCaches)
+					selectedCaches, err := selectCachesToDelete(targets, matchedCaches)
+					if err != nil {
+						return err
+					}
+					if len(selectedCaches) == 0 {
+						fmt.Println("No cache entries selected")
+						return nil
+					}
+					if err := checkDeletionLimits(cmd.Context(), artifactCache, f.DeletionLimits, selectedCaches); err != nil {
+						return err
+					}
 
-				prompt := &survey.Select{
-					Message: "Are you sure you want to delete the cache entries?",
-					Options: []string{"Delete", "Cancel"},
+					audit, err := newAuditRecorder(cmd.Context(), artifactCache, repo, f.Reason)
+					if err != nil {
+						return err
+					}
+					deletedCaches, err := deleteCachesById(cmd.Context(), artifactCache, selectedCaches, f.Parallel, audit)
+					if f.Summary {
+						if summaryErr := writeDeleteSummary(repo, deletedCaches); summaryErr != nil {
+							return summaryErr
+						}
+					}
+					return err
 				}
-				err = survey.AskOne(prompt, &choice)
-				if err != nil {
-					fmt.Println("Error occurred while taking input from user while trying to delete cache")
-					return types.HandledError{Message: "Error occurred while taking input from user while trying to delete cache.", InnerError: err}
+
+				if err := checkDeletionLimits(cmd.Context(), artifactCache, f.DeletionLimits, matchedCaches); err != nil {
+					return err
 				}
 
-				f.Confirm = choice == "Delete"
-				fmt.Println()
+				if !f.Confirm {
+					fmt.Printf("You're going to delete %s", internal.PrintSingularOrPlural(matchedCachesLen, "cache entry\n\n", "cache entries\n\n"))
+					internal.PrettyPrintTrimmedCacheList(matchedCaches)
+					printProtectedCaches(protectedCaches)
+
+					prompt := &survey.Select{
+						Message: "Are you sure you want to delete the cache entries?",
+						Options: []string{"Delete", "Cancel"},
+					}
+					err = survey.AskOne(prompt, &choice)
+					if err != nil {
+						fmt.Println("Error occurred while taking input from user while trying to delete cache")
+						return promptError(err)
+					}
+
+					f.Confirm = choice == "Delete"
+					fmt.Println()
+				} else {
+					printProtectedCaches(protectedCaches)
+				}
 			}
 			if f.Confirm {
-				cachesDeleted, err := artifactCache.DeleteCaches(queryParams)
+				if err := resolveCacheIds(cmd.Context(), artifactCache, targets); err != nil {
+					return internal.HttpErrorHandler(err, "The given repo does not exist.")
+				}
+				audit, err := newAuditRecorder(cmd.Context(), artifactCache, repo, f.Reason)
 				if err != nil {
-					return internal.HttpErrorHandler(err, fmt.Sprintf("Cache with input key '%s' does not exist", f.Key))
+					return err
 				}
+				results, report := deleteTargets(cmd.Context(), f, artifactCache, targets, audit)
 
-				if cachesDeleted > 0 {
-					fmt.Printf("%s Deleted %s with key '%s'\n", internal.RedTick(), internal.PrintSingularOrPlural(cachesDeleted, "cache entry", "cache entries"), f.Key)
-				} else {
-					fmt.Printf("Cache with input key '%s' does not exist\n", f.Key)
+				if f.Summary {
+					err = writeDeleteSummary(repo, report.Deleted())
+					if err != nil {
+						return err
+					}
 				}
+
+				if len(results) == 1 && !results[0].Target.IsId() {
+					result := results[0]
+					if result.Err != nil {
+						return internal.HttpErrorHandler(result.Err, fmt.Sprintf("Cache with input key '%s' does not exist", result.Target.Key))
+					}
+					if result.Count > 0 {
+						fmt.Printf("%s Deleted %s with key '%s'\n", internal.RedTick(), internal.PrintSingularOrPlural(result.Count, "cache entry", "cache entries"), result.Target.Key)
+					} else {
+						fmt.Printf("Cache with input key '%s' does not exist\n", result.Target.Key)
+					}
+					return nil
+				}
+				return printDeleteResults(results, report)
 			}
 			return nil
 		},
@@ -87,6 +185,13 @@ func NewCmdDelete() *cobra.Command {
 	deleteCmd.Flags().StringVarP(&f.Repo, "repo", "R", "", "Select another repository for finding actions cache.")
 	deleteCmd.Flags().StringVarP(&f.Branch, "branch", "B", "", "Filter by branch")
 	deleteCmd.Flags().BoolVar(&f.Confirm, "confirm", false, "Delete the cache without asking user for confirmation.")
+	deleteCmd.Flags().BoolVar(&f.Summary, "summary", false, "Append the deleted entries to the GitHub Actions job summary.")
+	deleteCmd.Flags().BoolVarP(&f.Select, "select", "s", false, "Choose which of the matched cache entries to delete.")
+	deleteCmd.Flags().StringVar(&f.FromFile, "from-file", "", "Read cache keys or ids to delete from a file, or - for stdin.")
+	deleteCmd.Flags().IntVar(&f.Parallel, "parallel", 1, "Number of concurrent delete requests between 1 and 20.")
+	addProtectionFlags(deleteCmd, &f.ProtectionOptions)
+	addDeletionLimitFlags(deleteCmd, &f.DeletionLimits)
+	deleteCmd.Flags().StringVar(&f.Reason, "reason", "", "Why the caches are deleted, recorded in the audit log.")
 	deleteCmd.SetHelpTemplate(getDeleteHelp())
 
 	return deleteCmd
@@ -97,34 +202,58 @@ func getDeleteHelp() string {
 gh-actions-cache: Works with GitHub Actions Cache. 
 
 USAGE:
-	gh actions-cache delete <key> [flags]
+	gh actions-cache delete <key>... [flags]
+	gh actions-cache delete --from-file <file|-> [flags]
 
 ARGUMENTS:
-	key		cache key which needs to be deleted
+	key		one or more cache keys which need to be deleted
 	
 FLAGS:
 	-R, --repo <[HOST/]owner/repo>		Select another repository using the [HOST/]OWNER/REPO format
 	-B, --branch <string>			Filter by branch
 	--confirm				Confirm deletion without prompting
+	--summary				Append a table of the deleted entries to $GITHUB_STEP_SUMMARY when set
+	-s, --select				Choose which of the matched entries to delete instead of deleting all of them
+	--from-file <file|->			Read cache keys or ids to delete, one per line, or the JSON output of list. Use - for stdin
+	--parallel <int>			Number of concurrent delete requests (default is 1, max is 20)
+	--protect-ref <ref>			Never delete the caches of a branch or full ref, can be repeated. Added to protected-refs of the configuration
+	--protect-key-prefix <prefix>		Never delete the caches whose key starts with prefix, can be repeated. Added to protected-key-prefixes of the configuration
+	--allow-protected			Delete the matched caches even when they are protected
+	--max-entries <int>			Refuse to delete more cache entries at once unless --force is given
+	--max-size <size>			Refuse to delete more than this size at once, e.g. 2GB, unless --force is given
+	--max-usage-percent <percent>		Refuse to delete more than this percentage of the cache usage at once unless --force is given
+	--force					Delete whatever --max-entries, --max-size and --max-usage-percent and their configuration
+	--reason <string>			Why the caches are deleted, recorded with every deletion in the audit log (see gh actions-cache audit)
 
 INHERITED FLAGS
 	--help		Show help for command
+	--verbose	Log every request with its status and timing, the remaining API rate limit and the retries on stderr
+	--debug		Also log the request headers, the body of failed responses and the cause of errors, with tokens redacted. Also enabled by GH_DEBUG
+	--max-retry-wait <duration>	Longest total time to wait while retrying a rate limited or failed request (default is 1m)
+	--timeout <duration>		Abort the command if it runs longer than the given duration, e.g. 5m
+	--cache-ttl <duration>		Store listings and usage on disk and send conditional requests to revalidate them for that long, e.g. 1h (disabled by default)
+	--no-cache			Bypass the responses stored with --cache-ttl
 
 EXAMPLES:
 	$ gh actions-cache delete Linux-node-f5dbf39c9d11eba80242ac13
+	$ gh actions-cache delete Linux-node-f5dbf39c9d11eba80242ac13 --select
+	$ gh actions-cache delete Linux-node-f5dbf39c9d11eba80242ac13 Linux-node-a68c45df0f45f888039d32cd
+	$ gh actions-cache list --key Linux-node- --format json | gh actions-cache delete --from-file - --parallel 8
+	$ gh actions-cache delete Linux-node-f5dbf39c9d11eba80242ac13 --confirm --protect-ref main
+	$ gh actions-cache delete --from-file stale-keys.txt --confirm --max-entries 50 --max-usage-percent 25
 `
 }
 
-func getCacheListWithExactMatch(f types.DeleteOptions, artifactCache service.ArtifactCacheService) ([]types.ActionsCache, error) {
+func getCacheListWithExactMatch(ctx context.Context, f types.DeleteOptions, artifactCache service.ArtifactCacheService) ([]types.Cache, error) {
 	listOption := types.ListOptions{BaseOptions: types.BaseOptions{Repo: f.Repo, Branch: f.Branch, Key: f.Key}, Limit: 100, Order: "", Sort: ""}
 	queryParams := url.Values{}
 
 	listOption.GenerateBaseQueryParams(queryParams)
-	caches, err := artifactCache.ListAllCaches(queryParams, f.Key)
+	caches, err := artifactCache.ListAllCaches(ctx, queryParams, f.Key)
 	if err != nil {
 		return nil, err
 	}
-	var exactMatchedKeys []types.ActionsCache
+	var exactMatchedKeys []types.Cache
 	for _, cache := range caches {
 		if strings.EqualFold(f.Key, cache.Key) {
 			exactMatchedKeys = append(exactMatchedKeys, cache)
@@ -132,3 +261,342 @@ func getCacheListWithExactMatch(f types.DeleteOptions, artifactCache service.Art
 	}
 	return exactMatchedKeys, nil
 }
+
+type deleteResult struct {
+	Target  internal.CacheTarget
+	Count   int
+	Deleted []types.Cache
+	Err     error
+	Skipped bool
+}
+
+// readDeleteTargets combines the keys given as arguments with the keys or ids read from fromFile, which may be -
+// for stdin.
+func readDeleteTargets(args []string, fromFile string, stdin io.Reader) ([]internal.CacheTarget, error) {
+	targets := make([]internal.CacheTarget, 0, len(args))
+	for _, key := range args {
+		targets = append(targets, internal.CacheTarget{Key: key})
+	}
+	if fromFile == "" {
+		return targets, nil
+	}
+
+	reader := stdin
+	if fromFile != "-" {
+		file, err := os.Open(fromFile)
+		if err != nil {
+			return nil, fmt.Errorf("could not open '%s': %w", fromFile, err)
+		}
+		defer file.Close()
+		reader = file
+	}
+
+	fileTargets, err := internal.ParseCacheTargets(reader)
+	if err != nil {
+		return nil, fmt.Errorf("could not read cache keys or ids from '%s': %w", fromFile, err)
+	}
+	return append(targets, fileTargets...), nil
+}
+
+// resolveDeleteTargets looks up the caches matching every target. Keys are matched exactly like a single key
+// delete, ids without details are looked up in the full listing. It returns the targets that matched at least one
+// unprotected cache along with the unprotected and the protected caches. With byId, keys are replaced by the ids
+// of their unprotected caches so that caches created since, which were not checked, cannot be deleted by key.
+func resolveDeleteTargets(ctx context.Context, f types.DeleteOptions, artifactCache service.ArtifactCacheService, targets []internal.CacheTarget, protection types.Protection, byId bool) ([]internal.CacheTarget, []types.Cache, []types.Cache, error) {
+	var cachesById map[int64]types.Cache
+	var matchedTargets []internal.CacheTarget
+	var matchedCaches, protectedCaches []types.Cache
+	for _, target := range targets {
+		var caches []types.Cache
+		switch {
+		case !target.IsId():
+			keyOptions := f
+			keyOptions.Key = target.Key
+			var err error
+			caches, err = getCacheListWithExactMatch(ctx, keyOptions, artifactCache)
+			if err != nil {
+				return nil, nil, nil, err
+			}
+		case target.Cache != nil:
+			caches = []types.Cache{*target.Cache}
+		default:
+			if cachesById == nil {
+				var err error
+				if cachesById, err = getCachesById(ctx, artifactCache); err != nil {
+					return nil, nil, nil, err
+				}
+			}
+			if cache, ok := cachesById[target.Id]; ok {
+				caches = []types.Cache{cache}
+				target.Cache = &cache
+			}
+		}
+
+		if len(caches) == 0 {
+			if len(targets) > 1 {
+				fmt.Printf("Cache with input %s does not exist\n", target)
+			}
+			continue
+		}
+		caches, protected := protection.Split(caches)
+		protectedCaches = append(protectedCaches, protected...)
+		if !byId || target.IsId() {
+			if len(caches) > 0 {
+				matchedTargets = append(matchedTargets, target)
+			}
+		} else {
+			for i := range caches {
+				matchedTargets = append(matchedTargets, internal.CacheTarget{Id: caches[i].Id, Cache: &caches[i]})
+			}
+		}
+		matchedCaches = append(matchedCaches, caches...)
+	}
+	return matchedTargets, matchedCaches, protectedCaches, nil
+}
+
+// resolveCacheIds looks up the ids given without details in the full listing, so that their deletion is reported,
+// summarized and audited with the key, ref, version and size of the cache. Ids missing from the listing are kept
+// as they are.
+func resolveCacheIds(ctx context.Context, artifactCache service.ArtifactCacheService, targets []internal.CacheTarget) error {
+	var cachesById map[int64]types.Cache
+	for i := range targets {
+		if !targets[i].IsId() || targets[i].Cache != nil {
+			continue
+		}
+		if cachesById == nil {
+			var err error
+			if cachesById, err = getCachesById(ctx, artifactCache); err != nil {
+				return err
+			}
+		}
+		if cache, ok := cachesById[targets[i].Id]; ok {
+			targets[i].Cache = &cache
+		}
+	}
+	return nil
+}
+
+func getCachesById(ctx context.Context, artifactCache service.ArtifactCacheService) (map[int64]types.Cache, error) {
+	listing, err := getAllCaches(ctx, artifactCache)
+	if err != nil {
+		return nil, err
+	}
+	cachesById := make(map[int64]types.Cache, len(listing))
+	for _, cache := range listing {
+		cachesById[cache.Id] = cache
+	}
+	return cachesById, nil
+}
+
+// deleteTargets deletes every target, keys through the delete by key API and ids one by one, and keeps going when
+// one of them fails.
+func deleteTargets(ctx context.Context, f types.DeleteOptions, artifactCache service.ArtifactCacheService, targets []internal.CacheTarget, audit *auditRecorder) ([]deleteResult, service.DeletionReport) {
+	jobs := make([]service.DeletionJob, 0, len(targets))
+	for _, target := range targets {
+		if target.IsId() {
+			cache := types.Cache{Id: target.Id}
+			if target.Cache != nil {
+				cache = *target.Cache
+			}
+			jobs = append(jobs, service.DeletionJob{Cache: cache})
+			continue
+		}
+		keyOptions := f
+		keyOptions.Key = target.Key
+		queryParams := url.Values{}
+		keyOptions.GenerateBaseQueryParams(queryParams)
+		jobs = append(jobs, service.DeletionJob{Query: queryParams})
+	}
+
+	report := newDeleter(artifactCache, f.Parallel, len(jobs), audit).Run(ctx, jobs)
+	results := make([]deleteResult, 0, len(targets))
+	for i, target := range targets {
+		jobResult := report.Results[i]
+		results = append(results, deleteResult{Target: target, Count: len(jobResult.Deleted), Deleted: jobResult.Deleted, Err: jobResult.Err, Skipped: jobResult.Skipped})
+	}
+	return results, report
+}
+
+// newDeleter returns a deleter for jobs deletions that records every deleted cache in the audit log as it goes and
+// draws a progress bar on stderr when it is a terminal.
+func newDeleter(artifactCache service.ArtifactCacheService, parallel int, jobs int, audit *auditRecorder) service.Deleter {
+	deleter := service.Deleter{ArtifactCache: artifactCache, Parallel: parallel, Finished: audit.Finished}
+	if jobs > 1 && ghTerm.IsTerminal(os.Stderr) {
+		deleter.Progress = internal.NewProgressBar(os.Stderr, "Deleting").Update
+	}
+	return deleter
+}
+
+func printDeleteResults(results []deleteResult, report service.DeletionReport) error {
+	failed, skipped, deleted := 0, 0, 0
+	for _, result := range results {
+		switch {
+		case result.Skipped:
+			skipped++
+			fmt.Printf("- Skipped input %s\n", result.Target)
+		case result.Err != nil:
+			failed++
+			fmt.Printf("%s Failed to delete cache with input %s: %s\n", internal.RedCross(), result.Target, internal.HttpErrorHandler(result.Err, "Cache does not exist").Message)
+		case result.Count == 0:
+			fmt.Printf("- Cache with input %s does not exist\n", result.Target)
+		default:
+			deleted += result.Count
+			fmt.Printf("%s Deleted %s with input %s\n", internal.RedTick(), internal.PrintSingularOrPlural(result.Count, "cache entry", "cache entries"), result.Target)
+		}
+	}
+
+	fmt.Printf("\nDeleted %s for %d of %d keys or ids, %s reclaimed\n", internal.PrintSingularOrPlural(deleted, "cache entry", "cache entries"), len(results)-failed-skipped, len(results), internal.FormatCacheSize(report.BytesReclaimed()))
+	if failed > 0 {
+		return types.HandledError{Message: fmt.Sprintf("Failed to delete caches for %d of %d keys or ids", failed, len(results)), Kind: types.ErrorKindPartialFailure}
+	}
+	if skipped > 0 {
+		return types.HandledError{Message: fmt.Sprintf("Interrupted before deleting caches for %d of %d keys or ids", skipped, len(results)), InnerError: types.ErrInterrupted, Kind: types.ErrorKindCancelled}
+	}
+	return nil
+}
+
+// selectCachesToDelete lets the user pick a subset of the matched caches, e.g. to keep the copy of the default
+// branch while deleting the copies of pull requests.
+func selectCachesToDelete(targets []internal.CacheTarget, caches []types.Cache) ([]types.Cache, error) {
+	singleKey := len(targets) == 1 && !targets[0].IsId()
+	options := make([]string, 0, len(caches))
+	for _, cache := range caches {
+		option := fmt.Sprintf("%s  version %s  %s", cache.Ref, shortVersion(cache.Version), internal.FormatCacheSize(cache.SizeInBytes))
+		if !singleKey {
+			option = fmt.Sprintf("%s  %s", cache.Key, option)
+		}
+		options = append(options, option)
+	}
+
+	message := "Select the cache entries to delete"
+	if singleKey {
+		message = fmt.Sprintf("Select the cache entries with key '%s' to delete", targets[0].Key)
+	}
+	prompt := &survey.MultiSelect{
+		Message:  message,
+		Options:  options,
+		PageSize: 20,
+	}
+	var selectedIndexes []int
+	err := askMultiSelect(prompt, &selectedIndexes)
+	if err != nil {
+		return nil, promptError(err)
+	}
+
+	selected := make([]types.Cache, 0, len(selectedIndexes))
+	for _, i := range selectedIndexes {
+		selected = append(selected, caches[i])
+	}
+	return selected, nil
+}
+
+// deleteCachesById deletes the given caches with at most parallel concurrent requests, reporting each failure
+// without stopping. It returns the caches that were deleted and an error if any deletion failed or was skipped.
+func deleteCachesById(ctx context.Context, artifactCache service.ArtifactCacheService, caches []types.Cache, parallel int, audit *auditRecorder) ([]types.Cache, error) {
+	report := newDeleter(artifactCache, parallel, len(caches), audit).Run(ctx, service.DeletionJobsById(caches))
+	return printDeletionReport(report)
+}
+
+// printDeletionReport prints the failures and the outcome of deletions by id. It returns the caches that were
+// deleted and an error if any deletion failed or was skipped.
+func printDeletionReport(report service.DeletionReport) ([]types.Cache, error) {
+	failures := report.Failures()
+	for _, failure := range failures {
+		cache := failure.Job.Cache
+		fmt.Printf("%s Failed to delete cache '%s' (%s): %s\n", internal.RedCross(), cache.Key, cache.Ref, internal.HttpErrorHandler(failure.Err, "Cache does not exist").Message)
+	}
+
+	deleted := report.Deleted()
+	skipped := len(report.Skipped())
+	fmt.Printf("%s Deleted %s, %s reclaimed\n", internal.RedTick(), internal.PrintSingularOrPlural(len(deleted), "cache entry", "cache entries"), internal.FormatCacheSize(report.BytesReclaimed()))
+	if gone := len(report.Gone()); gone > 0 {
+		fmt.Printf("- %s already deleted or evicted\n", internal.PrintSingularOrPlural(gone, "cache entry was", "cache entries were"))
+	}
+	if len(failures) > 0 {
+		return deleted, types.HandledError{Message: fmt.Sprintf("Failed to delete %s", internal.PrintSingularOrPlural(len(failures), "cache entry", "cache entries")), Kind: types.ErrorKindPartialFailure}
+	}
+	if skipped > 0 {
+		return deleted, types.HandledError{Message: fmt.Sprintf("Interrupted before deleting %s", internal.PrintSingularOrPlural(skipped, "cache entry", "cache entries")), InnerError: types.ErrInterrupted, Kind: types.ErrorKindCancelled}
+	}
+	return deleted, nil
+}
+
+// printProtectedCaches lists the matched caches skipped because they are protected.
+func printProtectedCaches(caches []types.Cache) {
+	if len(caches) == 0 {
+		return
+	}
+	fmt.Printf("Skipping %s protected by --protect-ref, --protect-key-prefix or the configuration\n\n", internal.PrintSingularOrPlural(len(caches), "cache entry", "cache entries"))
+	internal.PrettyPrintTrimmedCacheList(caches)
+}
+
+// checkDeletionLimits refuses to delete caches beyond the limits, listing the caches that would have been deleted.
+// The cache usage is only fetched for a percentage limit, which refuses the deletion when the usage is unknown.
+func checkDeletionLimits(ctx context.Context, artifactCache service.ArtifactCacheService, limits types.DeletionLimits, caches []types.Cache) error {
+	if !limits.Enabled() {
+		return nil
+	}
+
+	var size int64
+	for _, cache := range caches {
+		size += cache.SizeInBytes
+	}
+	var exceeded []string
+	if limits.MaxEntries > 0 && len(caches) > limits.MaxEntries {
+		exceeded = append(exceeded, fmt.Sprintf("more than the limit of %s", internal.PrintSingularOrPlural(limits.MaxEntries, "cache entry", "cache entries")))
+	}
+	if maxSize, err := types.ParseByteSize(limits.MaxSize); limits.MaxSize != "" && err == nil && size > maxSize {
+		exceeded = append(exceeded, fmt.Sprintf("more than the limit of %s", internal.FormatCacheSize(maxSize)))
+	}
+	if limits.MaxUsagePercent > 0 {
+		usage, err := artifactCache.GetCacheUsage(ctx)
+		if err != nil {
+			return internal.HttpErrorHandler(err, "The given repo does not exist.")
+		}
+		if usage <= 0 {
+			exceeded = append(exceeded, fmt.Sprintf("the cache usage could not be read to check the limit of %v%%", limits.MaxUsagePercent))
+		} else if percent := float64(size) * 100 / float64(usage); percent > limits.MaxUsagePercent {
+			exceeded = append(exceeded, fmt.Sprintf("%.1f%% of the %s in use, more than the limit of %v%%", percent, internal.FormatCacheSize(usage), limits.MaxUsagePercent))
+		}
+	}
+	if len(exceeded) == 0 {
+		return nil
+	}
+
+	fmt.Printf("These cache entries would have been deleted:\n\n")
+	internal.PrettyPrintTrimmedCacheList(caches)
+	return types.HandledError{
+		Message: fmt.Sprintf("Refusing to delete %s of %s, %s. Use --force to delete them anyway", internal.PrintSingularOrPlural(len(caches), "cache entry", "cache entries"), internal.FormatCacheSize(size), strings.Join(exceeded, " and ")),
+		Kind:    types.ErrorKindValidation,
+	}
+}
+
+func addDeletionLimitFlags(cmd *cobra.Command, l *types.DeletionLimits) {
+	cmd.Flags().IntVar(&l.MaxEntries, "max-entries", 0, "Refuse to delete more cache entries at once unless --force is given.")
+	cmd.Flags().StringVar(&l.MaxSize, "max-size", "", "Refuse to delete more than this size at once, e.g. 2GB, unless --force is given.")
+	cmd.Flags().Float64Var(&l.MaxUsagePercent, "max-usage-percent", 0, "Refuse to delete more than this percentage of the cache usage at once unless --force is given.")
+	cmd.Flags().BoolVar(&l.Force, "force", false, "Delete whatever the deletion limits.")
+}
+
+func addProtectionFlags(cmd *cobra.Command, o *types.ProtectionOptions) {
+	cmd.Flags().StringSliceVar(&o.ProtectRefs, "protect-ref", nil, "Never delete the caches of a branch or full ref, can be repeated.")
+	cmd.Flags().StringSliceVar(&o.ProtectKeyPrefixes, "protect-key-prefix", nil, "Never delete the caches whose key starts with prefix, can be repeated.")
+	cmd.Flags().BoolVar(&o.AllowProtected, "allow-protected", false, "Delete the matched caches even when they are protected.")
+}
+
+func writeDeleteSummary(repo ghRepo.Repository, caches []types.Cache) error {
+	err := internal.AppendStepSummary(func(w io.Writer) error {
+		return internal.WriteDeleteSummary(w, fmt.Sprintf("%s/%s", repo.Owner(), repo.Name()), caches)
+	})
+	if err != nil {
+		return types.HandledError{Message: "Could not write the job summary.", InnerError: err}
+	}
+	return nil
+}
+
+func shortVersion(version string) string {
+	if len(version) > 8 {
+		return version[:8]
+	}
+	return version
+}
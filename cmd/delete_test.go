@@ -1,11 +1,16 @@
 package cmd
 
 import (
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
+	"github.com/AlecAivazis/survey/v2"
 	"github.com/actions/gh-actions-cache/internal"
 	"github.com/actions/gh-actions-cache/types"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"gopkg.in/h2non/gock.v1"
 )
 
@@ -16,7 +21,7 @@ func TestDeleteWithIncorrectArguments(t *testing.T) {
 	cmd.SetArgs([]string{})
 	err := cmd.Execute()
 
-	assert.ErrorContains(t, err, "accepts 1 arg(s), received 0")
+	assert.ErrorContains(t, err, "accepts at least 1 arg(s) or --from-file, received 0")
 	assert.True(t, gock.IsDone(), internal.PrintPendingMocks(gock.Pending()))
 }
 
@@ -50,6 +55,7 @@ func TestDeleteWithIncorrectRepoForDeleteCaches(t *testing.T) {
 	var customError types.HandledError
 	if assert.ErrorAs(t, err, &customError) {
 		assert.Equal(t, "The given repo does not exist.", customError.Message)
+		assert.Equal(t, types.EXIT_NOT_FOUND, types.ExitCode(err))
 	}
 	assert.True(t, gock.IsDone(), internal.PrintPendingMocks(gock.Pending()))
 }
@@ -76,6 +82,7 @@ func TestDeleteSuccessWithConfirmFlagProvided(t *testing.T) {
 				]
 			}`)
 
+	mockUserLogin()
 	cmd := NewCmdDelete()
 	cmd.SetArgs([]string{"--repo", "testOrg/testRepo", "2022-06-29T13:33:49", "--confirm"})
 	err := cmd.Execute()
@@ -126,6 +133,7 @@ func TestDeleteWithUnauthorizedRequestForDeleteCaches(t *testing.T) {
 			"documentation_url": "https://docs.github.com/rest/actions/cache#delete-a-github-actions-cache-for-a-repository-using-a-cache-id"
 		}`)
 
+	mockUserLogin()
 	cmd := NewCmdDelete()
 	cmd.SetArgs([]string{"--repo", "testOrg/testRepo", "cacheKey", "--confirm"})
 	err := cmd.Execute()
@@ -133,21 +141,25 @@ func TestDeleteWithUnauthorizedRequestForDeleteCaches(t *testing.T) {
 	var customError types.HandledError
 	if assert.ErrorAs(t, err, &customError) {
 		assert.Equal(t, "Must have admin rights to Repository.", customError.Message)
+		assert.Equal(t, types.EXIT_FORBIDDEN, types.ExitCode(err))
 	}
 	assert.True(t, gock.IsDone(), internal.PrintPendingMocks(gock.Pending()))
 }
 
 func TestDeleteWithInternalServerErrorForDeleteCaches(t *testing.T) {
 	t.Cleanup(gock.Off)
+	useFastRetries(t, 1)
 
 	gock.New("https://api.github.com").
 		Delete("/repos/testOrg/testRepo/actions/caches").
+		Times(2).
 		Reply(500).
 		JSON(`{
 			"message": "Internal Server Error",
 			"documentation_url": "https://docs.github.com/rest/reference/actions#get-github-actions-cache-Delete-for-a-repository"
 		}`)
 
+	mockUserLogin()
 	cmd := NewCmdDelete()
 	cmd.SetArgs([]string{"--repo", "testOrg/testRepo", "cacheKey", "--confirm"})
 	err := cmd.Execute()
@@ -158,3 +170,440 @@ func TestDeleteWithInternalServerErrorForDeleteCaches(t *testing.T) {
 	}
 	assert.True(t, gock.IsDone(), internal.PrintPendingMocks(gock.Pending()))
 }
+
+func TestDeleteWithSummaryFlagWritesJobSummary(t *testing.T) {
+	t.Cleanup(gock.Off)
+	summaryPath := filepath.Join(t.TempDir(), "summary.md")
+	t.Setenv(internal.STEP_SUMMARY_ENV, summaryPath)
+
+	gock.New("https://api.github.com").
+		Delete("/repos/testOrg/testRepo/actions/caches").
+		MatchParam("key", "2022-06-29T13:33:49").
+		Reply(200).
+		JSON(`{
+				"total_count": 1,
+				"actions_caches": [
+					{
+						"id": 1293,
+						"ref": "refs/heads/main",
+						"key": "2022-06-29T13:33:49",
+						"version": "803758043e242677f6b8650742372d82ded436d99b2a8a09bc3b6ed77cd6aec2",
+						"last_accessed_at": "2022-06-29T13:33:52.280000000Z",
+						"created_at": "2022-06-29T13:33:52.280000000Z",
+						"size_in_bytes": 29747
+					}
+				]
+			}`)
+
+	mockUserLogin()
+	cmd := NewCmdDelete()
+	cmd.SetArgs([]string{"--repo", "testOrg/testRepo", "2022-06-29T13:33:49", "--confirm", "--summary"})
+	err := cmd.Execute()
+
+	assert.NoError(t, err)
+	content, err := os.ReadFile(summaryPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "1 cache entry deleted, 29.05 KB reclaimed")
+	assert.Contains(t, string(content), "| 2022-06-29T13:33:49 | refs/heads/main | 29.05 KB | 29747 |")
+	assert.True(t, gock.IsDone(), internal.PrintPendingMocks(gock.Pending()))
+}
+
+func TestDeleteWithSelectAndConfirmFlags(t *testing.T) {
+	t.Cleanup(gock.Off)
+
+	cmd := NewCmdDelete()
+	cmd.SetArgs([]string{"--repo", "testOrg/testRepo", "cacheKey", "--select", "--confirm"})
+	err := cmd.Execute()
+
+	assert.ErrorContains(t, err, "select and confirm flags cannot be used together")
+	assert.True(t, gock.IsDone(), internal.PrintPendingMocks(gock.Pending()))
+}
+
+func TestDeleteSelectedEntriesById(t *testing.T) {
+	t.Cleanup(gock.Off)
+	t.Cleanup(func() {
+		askMultiSelect = func(prompt *survey.MultiSelect, response *[]int) error {
+			return survey.AskOne(prompt, response)
+		}
+	})
+	askMultiSelect = func(prompt *survey.MultiSelect, response *[]int) error {
+		assert.Equal(t, []string{
+			"refs/heads/main  version 80375804  29.05 KB",
+			"refs/pull/2/merge  version 80375804  29.05 KB",
+		}, prompt.Options)
+		*response = []int{1}
+		return nil
+	}
+
+	gock.New("https://api.github.com").
+		Get("/repos/testOrg/testRepo/actions/caches").
+		MatchParam("key", "2022-06-29T13:33:49").
+		Reply(200).
+		JSON(`{
+				"total_count": 2,
+				"actions_caches": [
+					{
+						"id": 1293,
+						"ref": "refs/heads/main",
+						"key": "2022-06-29T13:33:49",
+						"version": "803758043e242677f6b8650742372d82ded436d99b2a8a09bc3b6ed77cd6aec2",
+						"last_accessed_at": "2022-06-29T13:33:52.280000000Z",
+						"created_at": "2022-06-29T13:33:52.280000000Z",
+						"size_in_bytes": 29747
+					},
+					{
+						"id": 1294,
+						"ref": "refs/pull/2/merge",
+						"key": "2022-06-29T13:33:49",
+						"version": "803758043e242677f6b8650742372d82ded436d99b2a8a09bc3b6ed77cd6aec2",
+						"last_accessed_at": "2022-06-29T13:33:52.280000000Z",
+						"created_at": "2022-06-29T13:33:52.280000000Z",
+						"size_in_bytes": 29747
+					}
+				]
+			}`)
+
+	gock.New("https://api.github.com").
+		Delete("/repos/testOrg/testRepo/actions/caches/1294").
+		Reply(204)
+
+	mockUserLogin()
+	cmd := NewCmdDelete()
+	cmd.SetArgs([]string{"--repo", "testOrg/testRepo", "2022-06-29T13:33:49", "--select"})
+	err := cmd.Execute()
+
+	assert.NoError(t, err)
+	assert.True(t, gock.IsDone(), internal.PrintPendingMocks(gock.Pending()))
+}
+
+func TestDeleteMultipleKeysWithConfirmFlag(t *testing.T) {
+	t.Cleanup(gock.Off)
+
+	gock.New("https://api.github.com").
+		Delete("/repos/testOrg/testRepo/actions/caches").
+		MatchParam("key", "keyOne").
+		Reply(200).
+		JSON(`{
+				"total_count": 1,
+				"actions_caches": [
+					{
+						"id": 1293,
+						"ref": "refs/heads/main",
+						"key": "keyOne",
+						"version": "803758043e242677f6b8650742372d82ded436d99b2a8a09bc3b6ed77cd6aec2",
+						"last_accessed_at": "2022-06-29T13:33:52.280000000Z",
+						"created_at": "2022-06-29T13:33:52.280000000Z",
+						"size_in_bytes": 29747
+					}
+				]
+			}`)
+
+	gock.New("https://api.github.com").
+		Delete("/repos/testOrg/testRepo/actions/caches").
+		MatchParam("key", "keyTwo").
+		Reply(404).
+		JSON(`{
+			"message": "Not Found",
+			"documentation_url": "https://docs.github.com/rest/actions/cache#delete-github-actions-caches-for-a-repository-using-a-cache-key"
+		}`)
+
+	mockUserLogin()
+	cmd := NewCmdDelete()
+	cmd.SetArgs([]string{"--repo", "testOrg/testRepo", "keyOne", "keyTwo", "--confirm"})
+	err := cmd.Execute()
+
+	var customError types.HandledError
+	if assert.ErrorAs(t, err, &customError) {
+		assert.Equal(t, "Failed to delete caches for 1 of 2 keys or ids", customError.Message)
+		assert.Equal(t, types.EXIT_PARTIAL_FAILURE, types.ExitCode(err))
+	}
+	assert.True(t, gock.IsDone(), internal.PrintPendingMocks(gock.Pending()))
+}
+
+func TestDeleteFromStdinWithKeysAndIds(t *testing.T) {
+	t.Cleanup(gock.Off)
+
+	gock.New("https://api.github.com").
+		Delete("/repos/testOrg/testRepo/actions/caches").
+		MatchParam("key", "keyOne").
+		Reply(200).
+		JSON(`{"total_count": 0, "actions_caches": []}`)
+
+	gock.New("https://api.github.com").
+		Get("/repos/testOrg/testRepo/actions/caches").
+		MatchParam("per_page", "100").
+		Reply(200).
+		JSON(PROTECTED_LISTING)
+
+	gock.New("https://api.github.com").
+		Delete("/repos/testOrg/testRepo/actions/caches/1294").
+		Reply(204)
+
+	mockUserLogin()
+	cmd := NewCmdDelete()
+	cmd.SetIn(strings.NewReader("# caches to remove\nkeyOne\n\n1294\n"))
+	cmd.SetArgs([]string{"--repo", "testOrg/testRepo", "--from-file", "-", "--confirm"})
+	err := cmd.Execute()
+
+	assert.NoError(t, err)
+	assert.True(t, gock.IsDone(), internal.PrintPendingMocks(gock.Pending()))
+}
+
+func TestDeleteFromFileWithListJSONOutput(t *testing.T) {
+	t.Cleanup(gock.Off)
+
+	gock.New("https://api.github.com").
+		Delete("/repos/testOrg/testRepo/actions/caches/1293").
+		Reply(204)
+
+	gock.New("https://api.github.com").
+		Delete("/repos/testOrg/testRepo/actions/caches/1294").
+		Reply(204)
+
+	path := filepath.Join(t.TempDir(), "caches.json")
+	require.NoError(t, os.WriteFile(path, []byte(`[
+		{"id": 1293, "ref": "refs/heads/main", "key": "keyOne", "size_in_bytes": 100},
+		{"id": 1294, "ref": "refs/pull/2/merge", "key": "keyOne", "size_in_bytes": 100}
+	]`), 0644))
+
+	mockUserLogin()
+	cmd := NewCmdDelete()
+	cmd.SetArgs([]string{"--repo", "testOrg/testRepo", "--from-file", path, "--confirm"})
+	err := cmd.Execute()
+
+	assert.NoError(t, err)
+	assert.True(t, gock.IsDone(), internal.PrintPendingMocks(gock.Pending()))
+}
+
+func TestDeleteWithInvalidParallelFlag(t *testing.T) {
+	cmd := NewCmdDelete()
+	cmd.SetArgs([]string{"--repo", "testOrg/testRepo", "keyOne", "--parallel", "0"})
+	err := cmd.Execute()
+
+	assert.Error(t, err)
+	assert.Equal(t, "0 is not a valid integer value for parallel flag. Allowed values: 1-20", err.Error())
+}
+
+func TestDeleteFromFileWithParallelDeletionsReportsFailures(t *testing.T) {
+	t.Cleanup(gock.Off)
+
+	gock.New("https://api.github.com").
+		Get("/repos/testOrg/testRepo/actions/caches").
+		MatchParam("per_page", "100").
+		Reply(200).
+		JSON(`{"total_count": 2, "actions_caches": [{"id": 1293, "key": "keyOne"}, {"id": 1295, "key": "keyThree"}]}`)
+
+	gock.New("https://api.github.com").
+		Delete("/repos/testOrg/testRepo/actions/caches/1293").
+		Reply(204)
+
+	gock.New("https://api.github.com").
+		Delete("/repos/testOrg/testRepo/actions/caches/1294").
+		Reply(404).
+		JSON(`{"message": "Not Found"}`)
+
+	gock.New("https://api.github.com").
+		Delete("/repos/testOrg/testRepo/actions/caches/1295").
+		Reply(204)
+
+	mockUserLogin()
+	cmd := NewCmdDelete()
+	cmd.SetIn(strings.NewReader("1293\n1294\n1295\n"))
+	cmd.SetArgs([]string{"--repo", "testOrg/testRepo", "--from-file", "-", "--confirm", "--parallel", "3"})
+	err := cmd.Execute()
+
+	var customError types.HandledError
+	if assert.ErrorAs(t, err, &customError) {
+		assert.Equal(t, "Failed to delete caches for 1 of 3 keys or ids", customError.Message)
+	}
+	assert.True(t, gock.IsDone(), internal.PrintPendingMocks(gock.Pending()))
+}
+
+const PROTECTED_LISTING = `{
+	"total_count": 2,
+	"actions_caches": [
+		{
+			"id": 1293,
+			"ref": "refs/heads/main",
+			"key": "2022-06-29T13:33:49",
+			"version": "803758043e242677f6b8650742372d82ded436d99b2a8a09bc3b6ed77cd6aec2",
+			"last_accessed_at": "2022-06-29T13:33:52.280000000Z",
+			"created_at": "2022-06-29T13:33:52.280000000Z",
+			"size_in_bytes": 29747
+		},
+		{
+			"id": 1294,
+			"ref": "refs/pull/2/merge",
+			"key": "2022-06-29T13:33:49",
+			"version": "803758043e242677f6b8650742372d82ded436d99b2a8a09bc3b6ed77cd6aec2",
+			"last_accessed_at": "2022-06-29T13:33:52.280000000Z",
+			"created_at": "2022-06-29T13:33:52.280000000Z",
+			"size_in_bytes": 29747
+		}
+	]
+}`
+
+func TestDeleteWithConfirmFlagSkipsProtectedRefs(t *testing.T) {
+	t.Cleanup(gock.Off)
+
+	gock.New("https://api.github.com").
+		Get("/repos/testOrg/testRepo/actions/caches").
+		MatchParam("key", "2022-06-29T13:33:49").
+		Reply(200).
+		JSON(PROTECTED_LISTING)
+
+	gock.New("https://api.github.com").
+		Delete("/repos/testOrg/testRepo/actions/caches/1294").
+		Reply(204)
+
+	mockUserLogin()
+	cmd := NewCmdDelete()
+	cmd.SetArgs([]string{"--repo", "testOrg/testRepo", "2022-06-29T13:33:49", "--confirm", "--protect-ref", "main"})
+	err := cmd.Execute()
+
+	assert.NoError(t, err)
+	assert.True(t, gock.IsDone(), internal.PrintPendingMocks(gock.Pending()))
+}
+
+func TestDeleteWithConfirmFlagRefusesWhenEveryMatchIsProtected(t *testing.T) {
+	t.Cleanup(gock.Off)
+
+	gock.New("https://api.github.com").
+		Get("/repos/testOrg/testRepo/actions/caches").
+		MatchParam("key", "2022-06-29T13:33:49").
+		Reply(200).
+		JSON(PROTECTED_LISTING)
+
+	cmd := NewCmdDelete()
+	cmd.SetArgs([]string{"--repo", "testOrg/testRepo", "2022-06-29T13:33:49", "--confirm", "--protect-key-prefix", "2022-06"})
+	err := cmd.Execute()
+
+	assert.ErrorContains(t, err, "All the matched cache entries are protected. Use --allow-protected to delete them")
+	assert.Equal(t, types.EXIT_VALIDATION, types.ExitCode(err))
+	assert.True(t, gock.IsDone(), internal.PrintPendingMocks(gock.Pending()))
+}
+
+func TestDeleteWithAllowProtectedFlagDeletesByKey(t *testing.T) {
+	t.Cleanup(gock.Off)
+
+	gock.New("https://api.github.com").
+		Delete("/repos/testOrg/testRepo/actions/caches").
+		MatchParam("key", "2022-06-29T13:33:49").
+		Reply(200).
+		JSON(PROTECTED_LISTING)
+
+	mockUserLogin()
+	cmd := NewCmdDelete()
+	cmd.SetArgs([]string{"--repo", "testOrg/testRepo", "2022-06-29T13:33:49", "--confirm", "--protect-ref", "main", "--allow-protected"})
+	err := cmd.Execute()
+
+	assert.NoError(t, err)
+	assert.True(t, gock.IsDone(), internal.PrintPendingMocks(gock.Pending()))
+}
+
+func TestDeleteWithConfirmFlagRefusesMoreThanMaxEntries(t *testing.T) {
+	t.Cleanup(gock.Off)
+
+	gock.New("https://api.github.com").
+		Get("/repos/testOrg/testRepo/actions/caches").
+		MatchParam("key", "2022-06-29T13:33:49").
+		Reply(200).
+		JSON(PROTECTED_LISTING)
+
+	cmd := NewCmdDelete()
+	cmd.SetArgs([]string{"--repo", "testOrg/testRepo", "2022-06-29T13:33:49", "--confirm", "--max-entries", "1"})
+	err := cmd.Execute()
+
+	assert.EqualError(t, err, "Refusing to delete 2 cache entries of 58.10 KB, more than the limit of 1 cache entry. Use --force to delete them anyway")
+	assert.Equal(t, types.EXIT_VALIDATION, types.ExitCode(err))
+	assert.True(t, gock.IsDone(), internal.PrintPendingMocks(gock.Pending()))
+}
+
+func TestDeleteWithConfirmFlagRefusesMoreThanMaxUsagePercent(t *testing.T) {
+	t.Cleanup(gock.Off)
+
+	gock.New("https://api.github.com").
+		Get("/repos/testOrg/testRepo/actions/caches").
+		MatchParam("key", "2022-06-29T13:33:49").
+		Reply(200).
+		JSON(PROTECTED_LISTING)
+
+	gock.New("https://api.github.com").
+		Get("/repos/testOrg/testRepo/actions/cache/usage").
+		Reply(200).
+		JSON(`{"full_name": "testOrg/testRepo", "active_caches_size_in_bytes": 118988, "active_caches_count": 4}`)
+
+	cmd := NewCmdDelete()
+	cmd.SetArgs([]string{"--repo", "testOrg/testRepo", "2022-06-29T13:33:49", "--confirm", "--max-usage-percent", "25", "--max-size", "1MB"})
+	err := cmd.Execute()
+
+	assert.EqualError(t, err, "Refusing to delete 2 cache entries of 58.10 KB, 50.0% of the 116.20 KB in use, more than the limit of 25%. Use --force to delete them anyway")
+	assert.True(t, gock.IsDone(), internal.PrintPendingMocks(gock.Pending()))
+}
+
+func TestDeleteWithConfirmFlagRefusesMaxUsagePercentWithoutUsage(t *testing.T) {
+	t.Cleanup(gock.Off)
+
+	gock.New("https://api.github.com").
+		Get("/repos/testOrg/testRepo/actions/caches").
+		MatchParam("key", "2022-06-29T13:33:49").
+		Reply(200).
+		JSON(PROTECTED_LISTING)
+
+	gock.New("https://api.github.com").
+		Get("/repos/testOrg/testRepo/actions/cache/usage").
+		Reply(200).
+		JSON(`{"full_name": "testOrg/testRepo", "active_caches_size_in_bytes": 0, "active_caches_count": 0}`)
+
+	cmd := NewCmdDelete()
+	cmd.SetArgs([]string{"--repo", "testOrg/testRepo", "2022-06-29T13:33:49", "--confirm", "--max-usage-percent", "25"})
+	err := cmd.Execute()
+
+	assert.EqualError(t, err, "Refusing to delete 2 cache entries of 58.10 KB, the cache usage could not be read to check the limit of 25%. Use --force to delete them anyway")
+	assert.Equal(t, types.EXIT_VALIDATION, types.ExitCode(err))
+	assert.True(t, gock.IsDone(), internal.PrintPendingMocks(gock.Pending()))
+}
+
+func TestDeleteWithForceFlagIgnoresDeletionLimits(t *testing.T) {
+	t.Cleanup(gock.Off)
+
+	gock.New("https://api.github.com").
+		Delete("/repos/testOrg/testRepo/actions/caches").
+		MatchParam("key", "2022-06-29T13:33:49").
+		Reply(200).
+		JSON(PROTECTED_LISTING)
+
+	mockUserLogin()
+	cmd := NewCmdDelete()
+	cmd.SetArgs([]string{"--repo", "testOrg/testRepo", "2022-06-29T13:33:49", "--confirm", "--max-entries", "1", "--force"})
+	err := cmd.Execute()
+
+	assert.NoError(t, err)
+	assert.True(t, gock.IsDone(), internal.PrintPendingMocks(gock.Pending()))
+}
+
+func TestDeleteWithinMaxEntriesDeletesById(t *testing.T) {
+	t.Cleanup(gock.Off)
+
+	gock.New("https://api.github.com").
+		Get("/repos/testOrg/testRepo/actions/caches").
+		MatchParam("key", "2022-06-29T13:33:49").
+		Reply(200).
+		JSON(PROTECTED_LISTING)
+
+	gock.New("https://api.github.com").
+		Delete("/repos/testOrg/testRepo/actions/caches/1293").
+		Reply(204)
+
+	gock.New("https://api.github.com").
+		Delete("/repos/testOrg/testRepo/actions/caches/1294").
+		Reply(204)
+
+	mockUserLogin()
+	cmd := NewCmdDelete()
+	cmd.SetArgs([]string{"--repo", "testOrg/testRepo", "2022-06-29T13:33:49", "--confirm", "--max-entries", "2"})
+	err := cmd.Execute()
+
+	assert.NoError(t, err)
+	assert.True(t, gock.IsDone(), internal.PrintPendingMocks(gock.Pending()))
+}
@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/actions/gh-actions-cache/service"
+	"github.com/stretchr/testify/require"
+)
+
+// useFastRetries lowers the retries of the HTTP clients for the duration of the test so that retried mocks don't
+// slow the test down.
+func useFastRetries(t *testing.T, maxRetries int) {
+	options, backoff := service.HttpOptions, service.INITIAL_BACKOFF
+	t.Cleanup(func() {
+		service.HttpOptions, service.INITIAL_BACKOFF = options, backoff
+	})
+	service.HttpOptions.MaxRetries = maxRetries
+	service.INITIAL_BACKOFF = time.Millisecond
+}
+
+// captureStdout returns what run writes to os.Stdout, where the commands write the listings through the terminal.
+func captureStdout(t *testing.T, run func()) string {
+	reader, writer, err := os.Pipe()
+	require.NoError(t, err)
+	stdout := os.Stdout
+	os.Stdout = writer
+	defer func() { os.Stdout = stdout }()
+
+	var out bytes.Buffer
+	done := make(chan struct{})
+	go func() {
+		_, _ = io.Copy(&out, reader)
+		close(done)
+	}()
+	run()
+	writer.Close()
+	<-done
+	reader.Close()
+	return out.String()
+}
+
+// useFailingStdout replaces os.Stdout with a closed file for the duration of the test, so that every write to it fails
+// like it does on a broken pipe.
+func useFailingStdout(t *testing.T) {
+	reader, writer, err := os.Pipe()
+	require.NoError(t, err)
+	reader.Close()
+	writer.Close()
+	stdout := os.Stdout
+	os.Stdout = writer
+	t.Cleanup(func() { os.Stdout = stdout })
+}
+
+// TestMain points the audit log of the deletions to a temporary directory instead of the home directory.
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "gh-actions-cache-state")
+	if err != nil {
+		panic(err)
+	}
+	os.Setenv("XDG_STATE_HOME", dir)
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
@@ -0,0 +1,200 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/AlecAivazis/survey/v2/terminal"
+	"github.com/actions/gh-actions-cache/internal"
+	"github.com/actions/gh-actions-cache/service"
+	"github.com/actions/gh-actions-cache/types"
+	ghTerm "github.com/cli/go-gh/pkg/term"
+	"github.com/spf13/cobra"
+)
+
+func NewCmdInteractive() *cobra.Command {
+	interactiveCommand := "interactive"
+	var repoFlag string
+	var parallel int
+	var protectionOptions types.ProtectionOptions
+	var limits types.DeletionLimits
+	var reason string
+
+	var interactiveCmd = &cobra.Command{
+		Use:     "interactive",
+		Aliases: []string{"browse"},
+		Short:   "Browses the actions cache and deletes the selected entries",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 0 {
+				return fmt.Errorf(fmt.Sprintf("Invalid argument(s). Expected 0 received %d", len(args)))
+			}
+
+			repo, err := internal.GetRepo(repoFlag)
+			if err != nil {
+				return err
+			}
+
+			// This will silence the usage (help) message as they are not needed for errors beyond this point
+			cmd.SilenceUsage = true
+
+			err = types.ValidateParallel(parallel)
+			if err != nil {
+				return err
+			}
+			err = limits.Validate()
+			if err != nil {
+				return err
+			}
+
+			term := ghTerm.FromEnv()
+			if !term.IsTerminalOutput() || !ghTerm.IsTerminal(os.Stdin) {
+				return types.HandledError{Message: "The interactive command requires a terminal. Use list and delete instead.", Kind: types.ErrorKindValidation}
+			}
+
+			artifactCache, err := service.NewArtifactCache(repo, interactiveCommand, VERSION)
+			if err != nil {
+				return types.HandledError{Message: err.Error(), InnerError: err}
+			}
+
+			caches, err := getAllCaches(cmd.Context(), artifactCache)
+			if err != nil {
+				return internal.HttpErrorHandler(err, "The given repo does not exist.")
+			}
+			if len(caches) == 0 {
+				fmt.Printf("There are no Actions caches currently present in this repo\n")
+				return nil
+			}
+
+			browser := internal.NewCacheBrowser(fmt.Sprintf("Actions caches in %s/%s", repo.Owner(), repo.Name()), caches)
+			action, err := runCacheBrowser(browser, term)
+			if err != nil {
+				return types.HandledError{Message: "Error occurred while reading input from the terminal.", InnerError: err}
+			}
+			if action != internal.BrowserDelete {
+				return nil
+			}
+
+			selected, protected := protectionOptions.Protection().Split(browser.Selected())
+			if len(selected) == 0 {
+				printProtectedCaches(protected)
+				return types.HandledError{Message: "All the selected cache entries are protected. Use --allow-protected to delete them", Kind: types.ErrorKindValidation}
+			}
+			if err := checkDeletionLimits(cmd.Context(), artifactCache, limits, selected); err != nil {
+				return err
+			}
+			fmt.Printf("You're going to delete %s", internal.PrintSingularOrPlural(len(selected), "cache entry\n\n", "cache entries\n\n"))
+			internal.PrettyPrintTrimmedCacheList(selected)
+			printProtectedCaches(protected)
+
+			var confirmation string
+			prompt := &survey.Select{
+				Message: "Are you sure you want to delete the selected cache entries?",
+				Options: []string{"Delete", "Cancel"},
+			}
+			err = survey.AskOne(prompt, &confirmation)
+			if err != nil {
+				return promptError(err)
+			}
+			fmt.Println()
+			if confirmation != "Delete" {
+				return nil
+			}
+
+			audit, err := newAuditRecorder(cmd.Context(), artifactCache, repo, reason)
+			if err != nil {
+				return err
+			}
+			_, err = deleteCachesById(cmd.Context(), artifactCache, selected, parallel, audit)
+			return err
+		},
+	}
+
+	interactiveCmd.Flags().StringVarP(&repoFlag, "repo", "R", "", "Select another repository for finding actions cache.")
+	interactiveCmd.Flags().IntVar(&parallel, "parallel", 1, "Number of concurrent delete requests between 1 and 20.")
+	addProtectionFlags(interactiveCmd, &protectionOptions)
+	addDeletionLimitFlags(interactiveCmd, &limits)
+	interactiveCmd.Flags().StringVar(&reason, "reason", "", "Why the caches are deleted, recorded in the audit log.")
+	interactiveCmd.SetHelpTemplate(getInteractiveHelp())
+
+	return interactiveCmd
+}
+
+// runCacheBrowser draws the browser on the alternate screen and feeds it key presses until it asks to quit or to
+// delete the selection.
+func runCacheBrowser(browser *internal.CacheBrowser, term ghTerm.Term) (internal.BrowserAction, error) {
+	runeReader := terminal.NewRuneReader(terminal.Stdio{In: os.Stdin, Out: os.Stdout, Err: os.Stderr})
+	if err := runeReader.SetTermMode(); err != nil {
+		return internal.BrowserQuit, err
+	}
+	defer func() { _ = runeReader.RestoreTermMode() }()
+
+	fmt.Print(internal.ENTER_ALT_SCREEN)
+	defer fmt.Print(internal.EXIT_ALT_SCREEN)
+
+	for {
+		width, height, err := term.Size()
+		if err != nil {
+			width, height = 80, 24
+		}
+		browser.Render(term.Out(), width, height)
+
+		key, _, err := runeReader.ReadRune()
+		if err != nil {
+			return internal.BrowserQuit, err
+		}
+		if action := browser.HandleKey(key); action != internal.BrowserContinue {
+			return action, nil
+		}
+	}
+}
+
+func getInteractiveHelp() string {
+	return `
+gh-actions-cache: Works with GitHub Actions Cache.
+
+USAGE:
+	gh actions-cache interactive [flags]
+
+ALIASES:
+	browse
+
+ARGUMENTS:
+	No Arguments
+
+FLAGS:
+	-R, --repo <[HOST/]owner/repo>		Select another repository using the [HOST/]OWNER/REPO format
+	--parallel <int>			Number of concurrent delete requests (default is 1, max is 20)
+	--protect-ref <ref>			Never delete the caches of a branch or full ref, can be repeated. Added to protected-refs of the configuration
+	--protect-key-prefix <prefix>		Never delete the caches whose key starts with prefix, can be repeated. Added to protected-key-prefixes of the configuration
+	--allow-protected			Delete the selected caches even when they are protected
+	--max-entries <int>			Refuse to delete more cache entries at once unless --force is given
+	--max-size <size>			Refuse to delete more than this size at once, e.g. 2GB, unless --force is given
+	--max-usage-percent <percent>		Refuse to delete more than this percentage of the cache usage at once unless --force is given
+	--force					Delete whatever --max-entries, --max-size and --max-usage-percent and their configuration
+	--reason <string>			Why the caches are deleted, recorded with every deletion in the audit log (see gh actions-cache audit)
+
+KEYS:
+	up/down, k/j	move the cursor
+	space		select or unselect the entry under the cursor
+	a		select or unselect all entries matching the filter
+	/		fuzzy filter by key or ref, enter or esc to stop typing
+	s		cycle the sort order (last-used/size/created-at/key)
+	r		reverse the sort order
+	d, enter	delete the selected entries after confirmation
+	q, esc		quit without deleting
+
+INHERITED FLAGS
+	--help		Show help for command
+	--verbose	Log every request with its status and timing, the remaining API rate limit and the retries on stderr
+	--debug		Also log the request headers, the body of failed responses and the cause of errors, with tokens redacted. Also enabled by GH_DEBUG
+	--max-retry-wait <duration>	Longest total time to wait while retrying a rate limited or failed request (default is 1m)
+	--timeout <duration>		Abort the command if it runs longer than the given duration, e.g. 5m
+	--cache-ttl <duration>		Store listings and usage on disk and send conditional requests to revalidate them for that long, e.g. 1h (disabled by default)
+	--no-cache			Bypass the responses stored with --cache-ttl
+
+EXAMPLES:
+	$ gh actions-cache interactive
+	$ gh actions-cache browse -R owner/repo
+`
+}
@@ -0,0 +1,33 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/actions/gh-actions-cache/internal"
+	"github.com/actions/gh-actions-cache/types"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/h2non/gock.v1"
+)
+
+func TestInteractiveWithIncorrectArguments(t *testing.T) {
+	t.Cleanup(gock.Off)
+
+	cmd := NewCmdInteractive()
+	cmd.SetArgs([]string{"keyValue"})
+	err := cmd.Execute()
+
+	assert.ErrorContains(t, err, "Invalid argument(s). Expected 0 received 1")
+	assert.True(t, gock.IsDone(), internal.PrintPendingMocks(gock.Pending()))
+}
+
+func TestInteractiveRequiresTerminal(t *testing.T) {
+	t.Cleanup(gock.Off)
+
+	cmd := NewCmdInteractive()
+	cmd.SetArgs([]string{"--repo", "testOrg/testRepo"})
+	err := cmd.Execute()
+
+	assert.ErrorContains(t, err, "The interactive command requires a terminal")
+	assert.Equal(t, types.EXIT_VALIDATION, types.ExitCode(err))
+	assert.True(t, gock.IsDone(), internal.PrintPendingMocks(gock.Pending()))
+}
@@ -1,13 +1,18 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
+	"io"
 	"net/url"
+	"os"
+	"time"
 
 	"github.com/actions/gh-actions-cache/internal"
 	"github.com/actions/gh-actions-cache/service"
 	"github.com/actions/gh-actions-cache/types"
 	"github.com/spf13/cobra"
+	ghRepo "github.com/cli/go-gh/pkg/repository"
 	ghTerm "github.com/cli/go-gh/pkg/term"
 )
 
@@ -45,26 +50,53 @@ func NewCmdList() *cobra.Command {
 			// This will be used to determine the if output is terminal
 			terminal := ghTerm.FromEnv()
 			isTerminalOutput := terminal.IsTerminalOutput()
+			isTableOutput := f.Format == "" || f.Format == "table"
 
-			if f.Branch == "" && f.Key == "" {
-				totalCacheSize, err := artifactCache.GetCacheUsage()
+			if f.Branch == "" && f.Key == "" && isTableOutput && !f.Watch {
+				totalCacheSize, err := artifactCache.GetCacheUsage(cmd.Context())
 				if err == nil && totalCacheSize > 0 && isTerminalOutput {
 					fmt.Printf("Total caches size %s\n\n", internal.FormatCacheSize(totalCacheSize))
 				}
 			}
 
-			queryParams := url.Values{}
-			f.GenerateQueryParams(queryParams)
-			listCacheResponse, err := artifactCache.ListCaches(queryParams)
+			if f.Watch {
+				return watchCacheList(cmd.Context(), artifactCache, f, repo, terminal)
+			}
+
+			if f.All && !isTableOutput && f.Format != "json" && !f.Summary && !f.Stable {
+				return streamCacheList(cmd.Context(), artifactCache, f, terminal.Out())
+			}
+
+			caches, totalCaches, err := fetchCacheList(cmd.Context(), artifactCache, f)
 			if err != nil {
 				return internal.HttpErrorHandler(err, "The given repo does not exist.")
 			}
 
-			totalCaches := listCacheResponse.TotalCount
-			caches := listCacheResponse.ActionsCaches
+			if f.Summary {
+				err = internal.AppendStepSummary(func(w io.Writer) error {
+					return internal.WriteListSummary(w, fmt.Sprintf("%s/%s", repo.Owner(), repo.Name()), caches)
+				})
+				if err != nil {
+					return types.HandledError{Message: "Could not write the job summary.", InnerError: err}
+				}
+			}
+
+			if f.Format == "json" {
+				if err := internal.WriteJSONCacheList(terminal.Out(), caches); err != nil {
+					return types.HandledError{Message: "Could not write the cache list.", InnerError: err}
+				}
+				return nil
+			}
+			if !isTableOutput {
+				if err := internal.WriteDelimitedCacheList(terminal.Out(), caches, internal.FormatDelimiter(f.Format)); err != nil {
+					return types.HandledError{Message: "Could not write the cache list.", InnerError: err}
+				}
+				return nil
+			}
+
 			if len(caches) > 0 {
 				if isTerminalOutput {
-					fmt.Printf("Showing %d of %d cache entries in %s/%s\n\n", displayedEntriesCount(len(caches), f.Limit), totalCaches, repo.Owner(), repo.Name())
+					fmt.Printf("Showing %d of %d cache entries in %s/%s\n\n", displayedEntriesCount(len(caches), f.Limit, f.All), totalCaches, repo.Owner(), repo.Name())
 				}
 				internal.PrettyPrintCacheList(caches)
 			} else if isTerminalOutput {
@@ -80,13 +112,136 @@ func NewCmdList() *cobra.Command {
 	listCmd.Flags().StringVarP(&f.Key, "key", "", "", "Filter by key")
 	listCmd.Flags().StringVarP(&f.Order, "order", "", "", "Order of caches returned (asc/desc)")
 	listCmd.Flags().StringVarP(&f.Sort, "sort", "", "", "Sort fetched caches (last-used/size/created-at)")
+	listCmd.Flags().StringVarP(&f.Format, "format", "", "table", "Output format (table/csv/tsv/json)")
+	listCmd.Flags().BoolVar(&f.All, "all", false, "Fetch all cache entries by following every page")
+	listCmd.Flags().BoolVar(&f.Summary, "summary", false, "Append the listed entries to the GitHub Actions job summary")
+	listCmd.Flags().BoolVarP(&f.Watch, "watch", "w", false, "Keep polling and redrawing the list until interrupted")
+	listCmd.Flags().DurationVar(&f.Interval, "interval", 10*time.Second, "Polling interval used with --watch")
+	listCmd.Flags().BoolVar(&f.Stable, "stable", false, "Scan again sorted by creation date when caches change during --all")
 	listCmd.SetHelpTemplate(getListHelp())
 
 	return listCmd
 }
 
-func displayedEntriesCount(totalCaches int, limit int) int {
-	if totalCaches < limit {
+func fetchCacheList(ctx context.Context, artifactCache service.ArtifactCacheService, f types.ListOptions) ([]types.Cache, int, error) {
+	queryParams := url.Values{}
+	f.GenerateQueryParams(queryParams)
+
+	if f.All {
+		inventory, err := artifactCache.ListCacheInventory(ctx, queryParams, f.Stable)
+		if err != nil {
+			return nil, 0, err
+		}
+		warnListingDrift(len(inventory.Caches), inventory.TotalCount, inventory.Scans, !f.Stable)
+		return inventory.Caches, len(inventory.Caches), nil
+	}
+
+	listCacheResponse, err := artifactCache.ListCaches(ctx, queryParams)
+	return listCacheResponse.Caches, listCacheResponse.TotalCount, err
+}
+
+// streamCacheList writes every cache as a delimited record as soon as its page arrives instead of waiting for the
+// whole listing.
+func streamCacheList(ctx context.Context, artifactCache service.ArtifactCacheService, f types.ListOptions, out io.Writer) error {
+	queryParams := url.Values{}
+	f.GenerateQueryParams(queryParams)
+	writer, err := internal.NewDelimitedCacheWriter(out, internal.FormatDelimiter(f.Format))
+	if err != nil {
+		return types.HandledError{Message: "Could not write the cache list.", InnerError: err}
+	}
+
+	seen := map[int64]bool{}
+	it := artifactCache.IterateCaches(ctx, queryParams, service.DEFAULT_PAGE_CONCURRENCY)
+	defer it.Close()
+	for it.Next() {
+		cache := it.Cache()
+		if seen[cache.Id] {
+			continue
+		}
+		seen[cache.Id] = true
+		if err := writer.Write(cache); err != nil {
+			return types.HandledError{Message: "Could not write the cache list.", InnerError: err}
+		}
+	}
+	if err := writer.Flush(); err != nil {
+		return types.HandledError{Message: "Could not write the cache list.", InnerError: err}
+	}
+	if it.Err() != nil {
+		return internal.HttpErrorHandler(it.Err(), "The given repo does not exist.")
+	}
+	warnListingDrift(len(seen), it.TotalCount(), 1, true)
+	return nil
+}
+
+// watchCacheList polls the cache list until interrupted. On a terminal the table is redrawn in place and rows that
+// are new, removed or accessed since the previous poll are highlighted.
+func watchCacheList(ctx context.Context, artifactCache service.ArtifactCacheService, f types.ListOptions, repo ghRepo.Repository, terminal ghTerm.Term) error {
+
+	isTerminalOutput := terminal.IsTerminalOutput()
+	var previous []types.Cache
+	for polls := 0; ; polls++ {
+		caches, totalCaches, err := fetchCacheList(ctx, artifactCache, f)
+		if err != nil {
+			return internal.HttpErrorHandler(err, "The given repo does not exist.")
+		}
+
+		var diff internal.SnapshotDiff
+		if polls > 0 {
+			diff = internal.DiffSnapshots(previous, caches)
+		}
+		previous = caches
+
+		if isTerminalOutput {
+			fmt.Print(internal.CLEAR_SCREEN)
+		}
+		fmt.Printf("Every %s: %s/%s at %s\n", f.Interval, repo.Owner(), repo.Name(), time.Now().Format(time.Kitchen))
+		if f.Branch == "" && f.Key == "" {
+			totalCacheSize, err := artifactCache.GetCacheUsage(ctx)
+			if err == nil && totalCacheSize > 0 {
+				fmt.Printf("Total caches size %s\n", internal.FormatCacheSize(totalCacheSize))
+			}
+		}
+		fmt.Printf("Showing %d of %d cache entries\n\n", displayedEntriesCount(len(caches), f.Limit, f.All), totalCaches)
+		internal.PrettyPrintWatchedCacheList(caches, diff)
+
+		select {
+		case <-service.Stopping(ctx):
+			return nil
+		case <-ctx.Done():
+			return nil
+		case <-time.After(f.Interval):
+		}
+	}
+}
+
+// getAllCaches lists every cache, scanning again when caches were created or evicted during the listing so that
+// reports, snapshots and bulk operations work from a consistent inventory.
+func getAllCaches(ctx context.Context, artifactCache service.ArtifactCacheService) ([]types.Cache, error) {
+	listOptions := types.ListOptions{All: true}
+	queryParams := url.Values{}
+	listOptions.GenerateQueryParams(queryParams)
+	inventory, err := artifactCache.ListCacheInventory(ctx, queryParams, true)
+	if err != nil {
+		return nil, err
+	}
+	warnListingDrift(len(inventory.Caches), inventory.TotalCount, inventory.Scans, false)
+	return inventory.Caches, nil
+}
+
+// warnListingDrift tells on stderr when the number of listed caches does not match the total count reported by
+// the API.
+func warnListingDrift(listed int, totalCount int, scans int, suggestStable bool) {
+	if listed == totalCount {
+		return
+	}
+	fmt.Fprintf(os.Stderr, "Warning: listed %s but the API reported %d after %s, caches were created or evicted during the listing\n", internal.PrintSingularOrPlural(listed, "cache entry", "cache entries"), totalCount, internal.PrintSingularOrPlural(scans, "scan", "scans"))
+	if suggestStable {
+		fmt.Fprintf(os.Stderr, "Use --stable to scan again sorted by creation date\n")
+	}
+}
+
+func displayedEntriesCount(totalCaches int, limit int, all bool) int {
+	if all || totalCaches < limit {
 		return totalCaches
 	}
 	return limit
@@ -109,13 +264,27 @@ FLAGS:
 	--key <string>				Filter by key
 	--order <string>			Order of caches returned (asc/desc)
 	--sort <string>				Sort fetched caches (last-used/size/created-at)
+	--format <string>			Output format (table/csv/tsv/json). csv and tsv print a header row with raw values
+	--all					Fetch all cache entries by following every page (ignores --limit)
+	--summary				Append a table of the listed entries to $GITHUB_STEP_SUMMARY when set
+	-w, --watch				Keep polling and redrawing the list until interrupted
+	--interval <duration>			Polling interval used with --watch (default is 10s)
+	--stable				With --all, scan again sorted by creation date when caches are created or evicted during the listing
 
 INHERITED FLAGS
 	--help		Show help for command
+	--verbose	Log every request with its status and timing, the remaining API rate limit and the retries on stderr
+	--debug		Also log the request headers, the body of failed responses and the cause of errors, with tokens redacted. Also enabled by GH_DEBUG
+	--max-retry-wait <duration>	Longest total time to wait while retrying a rate limited or failed request (default is 1m)
+	--timeout <duration>		Abort the command if it runs longer than the given duration, e.g. 5m
+	--cache-ttl <duration>		Store listings and usage on disk and send conditional requests to revalidate them for that long, e.g. 1h (disabled by default)
+	--no-cache			Bypass the responses stored with --cache-ttl
 
 EXAMPLES:
 	$ gh actions-cache list
 	$ gh actions-cache list --limit 100
 	$ gh actions-cache list --order desc
+	$ gh actions-cache list --all --format csv > caches.csv
+	$ gh actions-cache list --watch --interval 5s
 `
 }
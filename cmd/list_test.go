@@ -149,6 +149,7 @@ func TestListWithUnauthorizedRequestForListCaches(t *testing.T) {
 
 func TestListWithInternalServerErrorForListCaches(t *testing.T) {
 	t.Cleanup(gock.Off)
+	useFastRetries(t, 1)
 	gock.New("https://api.github.com").
 		Get("/repos/testOrg/testRepo/actions/cache/usage").
 		Reply(200).
@@ -160,6 +161,7 @@ func TestListWithInternalServerErrorForListCaches(t *testing.T) {
 
 	gock.New("https://api.github.com").
 		Get("/repos/testOrg/testRepo/actions/caches").
+		Times(2).
 		Reply(500).
 		JSON(`{
 			"message": "Internal Server Error",
@@ -212,3 +214,129 @@ func TestListSuccess(t *testing.T) {
 	assert.NoError(t, err)
 	assert.True(t, gock.IsDone(), internal.PrintPendingMocks(gock.Pending()))
 }
+
+func TestListWithIncorrectFormat(t *testing.T) {
+	t.Cleanup(gock.Off)
+
+	cmd := NewCmdList()
+	cmd.SetArgs([]string{"--format", "xml", "--repo", "testOrg/testRepo"})
+	err := cmd.Execute()
+
+	assert.ErrorContains(t, err, "xml is not a valid value for format flag. Allowed values: table/csv/tsv/json")
+	assert.True(t, gock.IsDone(), internal.PrintPendingMocks(gock.Pending()))
+}
+
+func TestListAllPagesAsCSV(t *testing.T) {
+	t.Cleanup(gock.Off)
+
+	gock.New("https://api.github.com").
+		Get("/repos/testOrg/testRepo/actions/caches").
+		MatchParam("per_page", "100").
+		Reply(200).
+		JSON(`{
+			"total_count": 101,
+			"actions_caches": [
+				{
+					"id": 29,
+					"ref": "refs/heads/master",
+					"key": "Linux-node-1",
+					"version": "7fcda33c1e1d849a13bcc06f49b9ab64efc01ca9dabe4d7a8d0d387feef4fc88",
+					"last_accessed_at": "2022-06-22T20:32:45.550000000Z",
+					"created_at": "2022-06-22T20:32:45.550000000Z",
+					"size_in_bytes": 2432967
+				}]
+			}`)
+
+	gock.New("https://api.github.com").
+		Get("/repos/testOrg/testRepo/actions/caches").
+		MatchParam("per_page", "100").
+		MatchParam("page", "2").
+		Reply(200).
+		JSON(`{
+			"total_count": 101,
+			"actions_caches": [
+				{
+					"id": 30,
+					"ref": "refs/heads/master",
+					"key": "Linux-node-2",
+					"version": "7fcda33c1e1d849a13bcc06f49b9ab64efc01ca9dabe4d7a8d0d387feef4fc88",
+					"last_accessed_at": "2022-06-22T20:32:45.550000000Z",
+					"created_at": "2022-06-22T20:32:45.550000000Z",
+					"size_in_bytes": 1024
+				}]
+			}`)
+
+	cmd := NewCmdList()
+	cmd.SetArgs([]string{"--repo", "testOrg/testRepo", "--all", "--format", "csv"})
+	var err error
+	out := captureStdout(t, func() { err = cmd.Execute() })
+
+	assert.NoError(t, err)
+	assert.Equal(t, `id,key,ref,version,size_in_bytes,created_at,last_accessed_at
+29,Linux-node-1,refs/heads/master,7fcda33c1e1d849a13bcc06f49b9ab64efc01ca9dabe4d7a8d0d387feef4fc88,2432967,2022-06-22T20:32:45.55Z,2022-06-22T20:32:45.55Z
+30,Linux-node-2,refs/heads/master,7fcda33c1e1d849a13bcc06f49b9ab64efc01ca9dabe4d7a8d0d387feef4fc88,1024,2022-06-22T20:32:45.55Z,2022-06-22T20:32:45.55Z
+`, out)
+	assert.True(t, gock.IsDone(), internal.PrintPendingMocks(gock.Pending()))
+}
+
+func TestListExitsAsInternalErrorWhenTheOutputFails(t *testing.T) {
+	t.Cleanup(gock.Off)
+	useFailingStdout(t)
+
+	for _, format := range []string{"json", "csv"} {
+		gock.New("https://api.github.com").
+			Get("/repos/testOrg/testRepo/actions/caches").
+			Reply(200).
+			JSON(`{"total_count": 1, "actions_caches": [{"id": 29, "ref": "refs/heads/master", "key": "Linux-node-1", "size_in_bytes": 1024}]}`)
+
+		cmd := NewCmdList()
+		cmd.SetArgs([]string{"--repo", "testOrg/testRepo", "--format", format})
+		err := cmd.Execute()
+
+		assert.ErrorContains(t, err, "Could not write the cache list.")
+		assert.Equal(t, types.EXIT_ERROR, types.ExitCode(err))
+	}
+	assert.True(t, gock.IsDone(), internal.PrintPendingMocks(gock.Pending()))
+}
+
+func TestListWatchWithIncorrectInterval(t *testing.T) {
+	t.Cleanup(gock.Off)
+
+	cmd := NewCmdList()
+	cmd.SetArgs([]string{"--watch", "--interval", "500ms", "--repo", "testOrg/testRepo"})
+	err := cmd.Execute()
+
+	assert.ErrorContains(t, err, "500ms is not a valid value for interval flag. It should be at least 1s")
+	assert.True(t, gock.IsDone(), internal.PrintPendingMocks(gock.Pending()))
+}
+
+func TestListWatchWithCSVFormat(t *testing.T) {
+	t.Cleanup(gock.Off)
+
+	cmd := NewCmdList()
+	cmd.SetArgs([]string{"--watch", "--format", "csv", "--repo", "testOrg/testRepo"})
+	err := cmd.Execute()
+
+	assert.ErrorContains(t, err, "watch flag can only be used with the table format")
+	assert.True(t, gock.IsDone(), internal.PrintPendingMocks(gock.Pending()))
+}
+
+func TestListStableWithoutAll(t *testing.T) {
+	t.Cleanup(gock.Off)
+
+	cmd := NewCmdList()
+	cmd.SetArgs([]string{"--stable", "--repo", "testOrg/testRepo"})
+	err := cmd.Execute()
+
+	assert.ErrorContains(t, err, "stable flag can only be used with the all flag")
+}
+
+func TestListStableWithSort(t *testing.T) {
+	t.Cleanup(gock.Off)
+
+	cmd := NewCmdList()
+	cmd.SetArgs([]string{"--all", "--stable", "--sort", "size", "--repo", "testOrg/testRepo"})
+	err := cmd.Execute()
+
+	assert.ErrorContains(t, err, "stable flag lists caches by creation date and cannot be used with the sort and order flags")
+}
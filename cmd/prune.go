@@ -0,0 +1,361 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/actions/gh-actions-cache/internal"
+	"github.com/actions/gh-actions-cache/service"
+	"github.com/actions/gh-actions-cache/types"
+	ghRepo "github.com/cli/go-gh/pkg/repository"
+	"github.com/spf13/cobra"
+)
+
+func NewCmdPrune() *cobra.Command {
+	pruneCommand := "prune"
+	f := types.PruneOptions{}
+
+	var pruneCmd = &cobra.Command{
+		Use:   "prune",
+		Short: "Deletes the caches matching filters, resumable from a journal",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 0 {
+				return fmt.Errorf(fmt.Sprintf("Invalid argument(s). Expected 0 received %d", len(args)))
+			}
+
+			err := f.Validate()
+			if err != nil {
+				return err
+			}
+
+			if f.Resume != "" {
+				if cmd.Flags().Changed("repo") {
+					return fmt.Errorf("resume flag cannot be used with the repo flag, the deletions resume in the repo of the journal")
+				}
+				// This will silence the usage (help) message as they are not needed for errors beyond this point
+				cmd.SilenceUsage = true
+				return resumePrune(cmd.Context(), f, pruneCommand)
+			}
+
+			repo, err := internal.GetRepo(f.Repo)
+			if err != nil {
+				return err
+			}
+
+			// This will silence the usage (help) message as they are not needed for errors beyond this point
+			cmd.SilenceUsage = true
+
+			artifactCache, err := service.NewArtifactCache(repo, pruneCommand, VERSION)
+			if err != nil {
+				return types.HandledError{Message: err.Error(), InnerError: err}
+			}
+
+			now := time.Now()
+			caches, err := listPruneCandidates(cmd.Context(), artifactCache, f, now)
+			if err != nil {
+				return internal.HttpErrorHandler(err, "The given repo does not exist.")
+			}
+			caches, protected := f.Protection().Split(caches)
+			if f.Out != "" {
+				printProtectedCaches(protected)
+				return writeCleanupPlan(f.Out, types.CleanupPlan{Repo: repoFullName(repo), CreatedAt: now.UTC(), Reason: f.Reason}, caches)
+			}
+			if len(caches) == 0 {
+				printProtectedCaches(protected)
+				fmt.Println("No cache entries to prune")
+				return nil
+			}
+			if err := checkDeletionLimits(cmd.Context(), artifactCache, f.DeletionLimits, caches); err != nil {
+				return err
+			}
+			confirmed, err := confirmDeletion(f.Confirm, caches, protected)
+			if err != nil || !confirmed {
+				return err
+			}
+
+			audit, err := newAuditRecorder(cmd.Context(), artifactCache, repo, f.Reason)
+			if err != nil {
+				return err
+			}
+			path := f.Journal
+			if path == "" {
+				if path, err = internal.DefaultJournalPath(repoFullName(repo), now); err != nil {
+					return types.HandledError{Message: "Could not locate the journal.", InnerError: err}
+				}
+			}
+			journal, err := internal.CreateJournal(path, types.JournalPlan{Repo: repoFullName(repo), CreatedAt: now.UTC(), Reason: f.Reason, Caches: caches})
+			if err != nil {
+				return types.HandledError{Message: fmt.Sprintf("Could not write the journal '%s'", path), InnerError: err}
+			}
+			fmt.Fprintf(os.Stderr, "Journal of the deletions: %s\n", path)
+			return runJournaledDeletion(cmd.Context(), artifactCache, repo, journal, caches, f.Parallel, audit, f.Summary, f.Journal == "")
+		},
+	}
+
+	pruneCmd.Flags().StringVarP(&f.Repo, "repo", "R", "", "Select another repository for finding actions cache.")
+	pruneCmd.Flags().StringVarP(&f.Branch, "branch", "B", "", "Only prune the caches of a branch or full ref.")
+	pruneCmd.Flags().StringVar(&f.Key, "key", "", "Only prune the caches whose key starts with it.")
+	pruneCmd.Flags().StringVar(&f.OlderThan, "older-than", "", "Only prune the caches last used longer ago than a duration like 72h or 30d.")
+	pruneCmd.Flags().BoolVar(&f.Confirm, "confirm", false, "Delete the caches without asking user for confirmation.")
+	pruneCmd.Flags().BoolVar(&f.Summary, "summary", false, "Append the deleted entries to the GitHub Actions job summary.")
+	pruneCmd.Flags().IntVar(&f.Parallel, "parallel", 1, "Number of concurrent delete requests between 1 and 20.")
+	pruneCmd.Flags().StringVar(&f.Journal, "journal", "", "Write the journal of the deletions to this file.")
+	pruneCmd.Flags().StringVar(&f.Resume, "resume", "", "Delete the caches of an interrupted prune that are left in its journal.")
+	pruneCmd.Flags().StringVar(&f.Out, "out", "", "Write the plan of the caches to delete to a file for apply, without deleting them.")
+	addProtectionFlags(pruneCmd, &f.ProtectionOptions)
+	addDeletionLimitFlags(pruneCmd, &f.DeletionLimits)
+	pruneCmd.Flags().StringVar(&f.Reason, "reason", "", "Why the caches are deleted, recorded in the audit log.")
+	pruneCmd.SetHelpTemplate(getPruneHelp())
+
+	return pruneCmd
+}
+
+// listPruneCandidates lists the caches matching the branch and key prefix, then keeps the ones last used before
+// the older-than age.
+func listPruneCandidates(ctx context.Context, artifactCache service.ArtifactCacheService, f types.PruneOptions, now time.Time) ([]types.Cache, error) {
+	listOptions := types.ListOptions{BaseOptions: f.BaseOptions, All: true}
+	queryParams := url.Values{}
+	listOptions.GenerateQueryParams(queryParams)
+	inventory, err := artifactCache.ListCacheInventory(ctx, queryParams, true)
+	if err != nil {
+		return nil, err
+	}
+	warnListingDrift(len(inventory.Caches), inventory.TotalCount, inventory.Scans, false)
+	if f.OlderThan == "" {
+		return inventory.Caches, nil
+	}
+
+	age, _ := types.ParseAge(f.OlderThan)
+	cutoff := now.Add(-age)
+	var caches []types.Cache
+	for _, cache := range inventory.Caches {
+		if cache.LastAccessedAt.Before(cutoff) {
+			caches = append(caches, cache)
+		}
+	}
+	return caches, nil
+}
+
+// writeCleanupPlan saves the caches to delete to a plan file for apply.
+func writeCleanupPlan(path string, plan types.CleanupPlan, caches []types.Cache) error {
+	var size int64
+	for _, cache := range caches {
+		plan.Entries = append(plan.Entries, types.NewPlanEntry(cache))
+		size += cache.SizeInBytes
+	}
+	if err := internal.SavePlan(path, plan); err != nil {
+		return types.HandledError{Message: fmt.Sprintf("Could not write the plan file '%s'", path), InnerError: err}
+	}
+	fmt.Printf("Planned the deletion of %s, %s, in %s\n", internal.PrintSingularOrPlural(len(caches), "cache entry", "cache entries"), internal.FormatCacheSize(size), path)
+	if len(caches) > 0 {
+		fmt.Printf("Delete them with: gh actions-cache apply %s\n", path)
+	}
+	return nil
+}
+
+// resumePrune deletes the caches left in the journal of an interrupted prune, in the repo of the journal. The caches
+// left by an apply are verified again to be unchanged since the plan.
+func resumePrune(ctx context.Context, f types.PruneOptions, pruneCommand string) error {
+	file, err := os.Open(f.Resume)
+	if err != nil {
+		return types.HandledError{Message: fmt.Sprintf("Could not read the journal '%s'", f.Resume), InnerError: err}
+	}
+	journal, err := internal.ReadJournal(file)
+	file.Close()
+	if err != nil {
+		return types.HandledError{Message: fmt.Sprintf("Could not read the journal '%s'", f.Resume), InnerError: err}
+	}
+
+	remaining := journal.Remaining()
+	if len(remaining) == 0 {
+		fmt.Printf("All the %s of the journal were already deleted\n", internal.PrintSingularOrPlural(len(journal.Plan.Caches), "cache entry", "cache entries"))
+		return nil
+	}
+	repo, err := internal.GetRepo(journal.Plan.Repo)
+	if err != nil {
+		return types.HandledError{Message: fmt.Sprintf("Invalid repo '%s' in the journal '%s'", journal.Plan.Repo, f.Resume), InnerError: err}
+	}
+	artifactCache, err := service.NewArtifactCache(repo, pruneCommand, VERSION)
+	if err != nil {
+		return types.HandledError{Message: err.Error(), InnerError: err}
+	}
+
+	if journal.Plan.Verify {
+		current, err := getAllCaches(ctx, artifactCache)
+		if err != nil {
+			return internal.HttpErrorHandler(err, "The given repo does not exist.")
+		}
+		var changes []internal.PlanChange
+		remaining, changes = internal.VerifyPlan(journalCleanupPlan(journal.Plan, remaining), current)
+		printPlanChanges(changes)
+		if len(remaining) == 0 {
+			fmt.Println("None of the planned cache entries are left to delete")
+			return nil
+		}
+	}
+
+	remaining, protected := f.Protection().Split(remaining)
+	if len(remaining) == 0 {
+		printProtectedCaches(protected)
+		return types.HandledError{Message: "All the cache entries left in the journal are protected. Use --allow-protected to delete them", Kind: types.ErrorKindValidation}
+	}
+	fmt.Printf("Resuming the prune of %s planned on %s: %d left\n", journal.Plan.Repo, journal.Plan.CreatedAt.Format(time.RFC3339), len(remaining))
+	confirmed, err := confirmDeletion(f.Confirm, remaining, protected)
+	if err != nil || !confirmed {
+		return err
+	}
+
+	reason := f.Reason
+	if reason == "" {
+		reason = journal.Plan.Reason
+	}
+	audit, err := newAuditRecorder(ctx, artifactCache, repo, reason)
+	if err != nil {
+		return err
+	}
+	writer, err := internal.OpenJournal(f.Resume)
+	if err != nil {
+		return types.HandledError{Message: fmt.Sprintf("Could not write the journal '%s'", f.Resume), InnerError: err}
+	}
+	return runJournaledDeletion(ctx, artifactCache, repo, writer, remaining, f.Parallel, audit, f.Summary, false)
+}
+
+// confirmDeletion lists the caches and asks to delete them, unless confirmed already.
+func confirmDeletion(confirmed bool, caches []types.Cache, protected []types.Cache) (bool, error) {
+	if confirmed {
+		printProtectedCaches(protected)
+		return true, nil
+	}
+
+	fmt.Printf("You're going to delete %s", internal.PrintSingularOrPlural(len(caches), "cache entry\n\n", "cache entries\n\n"))
+	internal.PrettyPrintTrimmedCacheList(caches)
+	printProtectedCaches(protected)
+
+	var confirmation string
+	prompt := &survey.Select{
+		Message: "Are you sure you want to delete the cache entries?",
+		Options: []string{"Delete", "Cancel"},
+	}
+	if err := survey.AskOne(prompt, &confirmation); err != nil {
+		return false, promptError(err)
+	}
+	fmt.Println()
+	return confirmation == "Delete", nil
+}
+
+// runJournaledDeletion deletes the caches by id and records every finished deletion in the audit log, then in the
+// journal, counting the caches already deleted or evicted as finished. Auditing first means a deletion cut short
+// between the two is attempted again on resume, and found gone, rather than left out of the audit log. When
+// deletions are left, it tells how to resume them. A journal at the default path is removed once every deletion
+// finished. With summary, the deleted caches are appended to the job summary.
+func runJournaledDeletion(ctx context.Context, artifactCache service.ArtifactCacheService, repo ghRepo.Repository, journal *internal.JournalWriter, caches []types.Cache, parallel int, audit *auditRecorder, summary bool, removeWhenDone bool) error {
+	var journalErr error
+	deleter := newDeleter(artifactCache, parallel, len(caches), audit)
+	deleter.IgnoreNotFound = true
+	deleter.Finished = func(result service.DeletionResult) {
+		audit.Finished(result)
+		if result.Err != nil || journalErr != nil {
+			return
+		}
+		status := types.JOURNAL_DELETED
+		if result.Gone {
+			status = types.JOURNAL_GONE
+		}
+		journalErr = journal.Record(result.Job.Cache.Id, status)
+	}
+	report := deleter.Run(ctx, service.DeletionJobsById(caches))
+	if closeErr := journal.Close(); journalErr == nil {
+		journalErr = closeErr
+	}
+
+	deleted, err := printDeletionReport(report)
+	if journalErr != nil {
+		fmt.Fprintf(os.Stderr, "Warning: could not write the journal '%s': %s\n", journal.Path, journalErr)
+	}
+	if err != nil || journalErr != nil {
+		fmt.Fprintf(os.Stderr, "Resume the deletions with: gh actions-cache prune --resume %s\n", journal.Path)
+	} else if removeWhenDone {
+		_ = os.Remove(journal.Path)
+	}
+	if summary {
+		if summaryErr := writeDeleteSummary(repo, deleted); summaryErr != nil && err == nil {
+			return summaryErr
+		}
+	}
+	return err
+}
+
+// journalCleanupPlan is the plan of the caches left in the journal of an apply, as they were when they were planned.
+func journalCleanupPlan(plan types.JournalPlan, caches []types.Cache) types.CleanupPlan {
+	cleanupPlan := types.CleanupPlan{Repo: plan.Repo, CreatedAt: plan.CreatedAt, Reason: plan.Reason}
+	for _, cache := range caches {
+		cleanupPlan.Entries = append(cleanupPlan.Entries, types.NewPlanEntry(cache))
+	}
+	return cleanupPlan
+}
+
+func repoFullName(repo ghRepo.Repository) string {
+	return fmt.Sprintf("%s/%s/%s", repo.Host(), repo.Owner(), repo.Name())
+}
+
+func getPruneHelp() string {
+	return `
+gh-actions-cache: Works with GitHub Actions Cache.
+
+USAGE:
+	gh actions-cache prune [flags]
+	gh actions-cache prune --resume <journal> [flags]
+	gh actions-cache prune --out <plan> [flags]
+
+ARGUMENTS:
+	No Arguments
+
+FLAGS:
+	-R, --repo <[HOST/]owner/repo>		Select another repository using the [HOST/]OWNER/REPO format
+	-B, --branch <string>			Only prune the caches of a branch or full ref
+	--key <string>				Only prune the caches whose key starts with it
+	--older-than <duration>			Only prune the caches last used longer ago than a duration like 72h or 30d
+	--confirm				Confirm deletion without prompting
+	--summary				Append a table of the deleted entries to $GITHUB_STEP_SUMMARY when set, also when resuming
+	--parallel <int>			Number of concurrent delete requests (default is 1, max is 20)
+	--journal <file>			Write the journal of the deletions to this file instead of the default path
+	--resume <journal>			Delete the caches left in the journal of an interrupted prune or apply, in the repo of the journal
+	--out <plan>				Write the caches to delete to a JSON plan file without deleting them, see gh actions-cache apply
+	--protect-ref <ref>			Never delete the caches of a branch or full ref, can be repeated. Added to protected-refs of the configuration
+	--protect-key-prefix <prefix>		Never delete the caches whose key starts with prefix, can be repeated. Added to protected-key-prefixes of the configuration
+	--allow-protected			Delete the matched caches even when they are protected
+	--max-entries <int>			Refuse to delete more cache entries at once unless --force is given
+	--max-size <size>			Refuse to delete more than this size at once, e.g. 2GB, unless --force is given
+	--max-usage-percent <percent>		Refuse to delete more than this percentage of the cache usage at once unless --force is given
+	--force					Delete whatever --max-entries, --max-size and --max-usage-percent and their configuration
+	--reason <string>			Why the caches are deleted, recorded with every deletion in the audit log (see gh actions-cache audit)
+
+JOURNAL:
+	Before deleting, prune and apply write the planned cache ids to a JSON Lines journal, then append every id whose
+	deletion finished. Caches already deleted or evicted (404) count as finished. The journal is kept in
+	~/.local/state/gh-actions-cache/journals ($XDG_STATE_HOME when set) until every deletion finished, and
+	prune --resume <journal> deletes the ones left after an interruption, a network drop or a rate limit, in the
+	repo of the journal. The ones left by apply are deleted only while unchanged since its plan. The deletion limits
+	are only checked when the prune is planned. delete and interactive write no journal, use prune, or prune --out
+	and apply, for deletions that may need to be resumed.
+
+INHERITED FLAGS
+	--help		Show help for command
+	--verbose	Log every request with its status and timing, the remaining API rate limit and the retries on stderr
+	--debug		Also log the request headers, the body of failed responses and the cause of errors, with tokens redacted. Also enabled by GH_DEBUG
+	--max-retry-wait <duration>	Longest total time to wait while retrying a rate limited or failed request (default is 1m)
+	--timeout <duration>		Abort the command if it runs longer than the given duration, e.g. 5m
+	--cache-ttl <duration>		Store listings and usage on disk and send conditional requests to revalidate them for that long, e.g. 1h (disabled by default)
+	--no-cache			Bypass the responses stored with --cache-ttl
+
+EXAMPLES:
+	$ gh actions-cache prune --older-than 30d --confirm
+	$ gh actions-cache prune --branch feature/login --key Linux-node- --parallel 8 --reason "branch merged"
+	$ gh actions-cache prune --older-than 30d --out plan.json
+	$ gh actions-cache prune --resume ~/.local/state/gh-actions-cache/journals/github.com-octo-org-octo-repo-20240131T100000Z.jsonl --confirm
+`
+}
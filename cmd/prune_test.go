@@ -0,0 +1,216 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/actions/gh-actions-cache/internal"
+	"github.com/actions/gh-actions-cache/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/h2non/gock.v1"
+)
+
+const PRUNE_LISTING = `{
+	"total_count": 3,
+	"actions_caches": [
+		{
+			"id": 11,
+			"ref": "refs/heads/main",
+			"key": "Linux-node-a",
+			"version": "803758043e242677f6b8650742372d82ded436d99b2a8a09bc3b6ed77cd6aec2",
+			"last_accessed_at": "2022-06-29T13:33:52.280000000Z",
+			"created_at": "2022-06-29T13:33:52.280000000Z",
+			"size_in_bytes": 1024
+		},
+		{
+			"id": 12,
+			"ref": "refs/heads/main",
+			"key": "Linux-node-b",
+			"version": "803758043e242677f6b8650742372d82ded436d99b2a8a09bc3b6ed77cd6aec2",
+			"last_accessed_at": "2022-06-30T13:33:52.280000000Z",
+			"created_at": "2022-06-29T13:33:52.280000000Z",
+			"size_in_bytes": 2048
+		},
+		{
+			"id": 13,
+			"ref": "refs/heads/main",
+			"key": "Linux-node-c",
+			"version": "803758043e242677f6b8650742372d82ded436d99b2a8a09bc3b6ed77cd6aec2",
+			"last_accessed_at": "2099-01-01T00:00:00.000000000Z",
+			"created_at": "2022-06-29T13:33:52.280000000Z",
+			"size_in_bytes": 4096
+		}
+	]
+}`
+
+func readJournalFile(t *testing.T, path string) types.DeletionJournal {
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	defer file.Close()
+	journal, err := internal.ReadJournal(file)
+	require.NoError(t, err)
+	return journal
+}
+
+func TestPruneWithoutFilters(t *testing.T) {
+	cmd := NewCmdPrune()
+	cmd.SetArgs([]string{"--repo", "testOrg/testRepo"})
+	err := cmd.Execute()
+
+	assert.ErrorContains(t, err, "at least one of the branch, key and older-than flags is required, or resume")
+}
+
+func TestPruneResumeWithFilters(t *testing.T) {
+	cmd := NewCmdPrune()
+	cmd.SetArgs([]string{"--resume", "journal.jsonl", "--older-than", "30d"})
+	err := cmd.Execute()
+
+	assert.ErrorContains(t, err, "resume flag cannot be used with the branch, key, older-than, journal and out flags")
+}
+
+func TestPruneResumeWithRepo(t *testing.T) {
+	cmd := NewCmdPrune()
+	cmd.SetArgs([]string{"--resume", "journal.jsonl", "--repo", "testOrg/otherRepo"})
+	err := cmd.Execute()
+
+	assert.ErrorContains(t, err, "resume flag cannot be used with the repo flag")
+	assert.Equal(t, types.EXIT_VALIDATION, types.ExitCode(err))
+}
+
+func TestPruneWithInvalidOlderThan(t *testing.T) {
+	cmd := NewCmdPrune()
+	cmd.SetArgs([]string{"--repo", "testOrg/testRepo", "--older-than", "a month"})
+	err := cmd.Execute()
+
+	assert.ErrorContains(t, err, "a month is not a valid value for older-than flag. Expected a duration like 72h or 30d")
+}
+
+func TestPruneResumesFromTheJournalAfterAFailure(t *testing.T) {
+	t.Cleanup(gock.Off)
+	useFastRetries(t, 0)
+	auditPath := useAuditLog(t)
+	journalPath := filepath.Join(t.TempDir(), "prune.jsonl")
+
+	gock.New("https://api.github.com").
+		Get("/repos/testOrg/testRepo/actions/caches").
+		MatchParam("ref", "refs/heads/main").
+		MatchParam("per_page", "100").
+		Reply(200).
+		JSON(PRUNE_LISTING)
+	gock.New("https://api.github.com").
+		Delete("/repos/testOrg/testRepo/actions/caches/11").
+		Reply(204)
+	gock.New("https://api.github.com").
+		Delete("/repos/testOrg/testRepo/actions/caches/12").
+		Reply(403).
+		JSON(`{"message": "Resource not accessible by integration"}`)
+
+	mockUserLogin()
+	cmd := NewCmdPrune()
+	cmd.SetArgs([]string{"--repo", "testOrg/testRepo", "--branch", "main", "--older-than", "30d", "--confirm", "--journal", journalPath})
+	err := cmd.Execute()
+
+	var handledError types.HandledError
+	require.ErrorAs(t, err, &handledError)
+	assert.Equal(t, types.ErrorKindPartialFailure, handledError.Kind)
+	journal := readJournalFile(t, journalPath)
+	assert.Equal(t, "github.com/testOrg/testRepo", journal.Plan.Repo)
+	assert.Len(t, journal.Plan.Caches, 2)
+	assert.Equal(t, map[int64]string{11: types.JOURNAL_DELETED}, journal.Completed)
+	assert.True(t, gock.IsDone(), internal.PrintPendingMocks(gock.Pending()))
+
+	gock.New("https://api.github.com").
+		Delete("/repos/testOrg/testRepo/actions/caches/12").
+		Reply(404).
+		JSON(`{"message": "Not Found"}`)
+
+	mockUserLogin()
+	cmd = NewCmdPrune()
+	cmd.SetArgs([]string{"--resume", journalPath, "--confirm"})
+	err = cmd.Execute()
+
+	require.NoError(t, err)
+	journal = readJournalFile(t, journalPath)
+	assert.Empty(t, journal.Remaining())
+	assert.Equal(t, types.JOURNAL_GONE, journal.Completed[12])
+	assert.True(t, gock.IsDone(), internal.PrintPendingMocks(gock.Pending()))
+
+	file, err := os.Open(auditPath)
+	require.NoError(t, err)
+	defer file.Close()
+	records, err := internal.ReadAuditRecords(file, types.AuditFilter{})
+	require.NoError(t, err)
+	if assert.Len(t, records, 1) {
+		assert.Equal(t, int64(11), records[0].CacheId)
+	}
+}
+
+func TestPruneRemovesTheDefaultJournalWhenDone(t *testing.T) {
+	t.Cleanup(gock.Off)
+	useAuditLog(t)
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+
+	gock.New("https://api.github.com").
+		Get("/repos/testOrg/testRepo/actions/caches").
+		MatchParam("key", "Linux-node-a").
+		Reply(200).
+		JSON(`{"total_count": 1, "actions_caches": [{"id": 11, "ref": "refs/heads/main", "key": "Linux-node-a", "last_accessed_at": "2022-06-29T13:33:52.280000000Z", "created_at": "2022-06-29T13:33:52.280000000Z", "size_in_bytes": 1024}]}`)
+	gock.New("https://api.github.com").
+		Delete("/repos/testOrg/testRepo/actions/caches/11").
+		Reply(204)
+
+	mockUserLogin()
+	cmd := NewCmdPrune()
+	cmd.SetArgs([]string{"--repo", "testOrg/testRepo", "--key", "Linux-node-a", "--confirm"})
+	err := cmd.Execute()
+
+	require.NoError(t, err)
+	journals, err := os.ReadDir(filepath.Join(os.Getenv("XDG_STATE_HOME"), "gh-actions-cache", "journals"))
+	require.NoError(t, err)
+	assert.Empty(t, journals)
+	assert.True(t, gock.IsDone(), internal.PrintPendingMocks(gock.Pending()))
+}
+
+func TestPruneWithSummaryFlagWritesJobSummaryAlsoWhenResuming(t *testing.T) {
+	t.Cleanup(gock.Off)
+	useFastRetries(t, 0)
+	useAuditLog(t)
+	summaryPath := filepath.Join(t.TempDir(), "summary.md")
+	t.Setenv(internal.STEP_SUMMARY_ENV, summaryPath)
+	journalPath := filepath.Join(t.TempDir(), "prune.jsonl")
+
+	gock.New("https://api.github.com").
+		Get("/repos/testOrg/testRepo/actions/caches").
+		MatchParam("per_page", "100").
+		Reply(200).
+		JSON(PRUNE_LISTING)
+	gock.New("https://api.github.com").
+		Delete("/repos/testOrg/testRepo/actions/caches/11").
+		Reply(204)
+	gock.New("https://api.github.com").
+		Delete("/repos/testOrg/testRepo/actions/caches/12").
+		Reply(403).
+		JSON(`{"message": "Resource not accessible by integration"}`)
+
+	mockUserLogin()
+	cmd := NewCmdPrune()
+	cmd.SetArgs([]string{"--repo", "testOrg/testRepo", "--older-than", "30d", "--confirm", "--summary", "--journal", journalPath})
+	assert.Error(t, cmd.Execute())
+
+	gock.New("https://api.github.com").
+		Delete("/repos/testOrg/testRepo/actions/caches/12").
+		Reply(204)
+
+	mockUserLogin()
+	cmd = NewCmdPrune()
+	cmd.SetArgs([]string{"--resume", journalPath, "--confirm", "--summary"})
+	require.NoError(t, cmd.Execute())
+	assert.True(t, gock.IsDone(), internal.PrintPendingMocks(gock.Pending()))
+
+	content, err := os.ReadFile(summaryPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "| Linux-node-a | refs/heads/main | 1.00 KB | 1024 |")
+	assert.Contains(t, string(content), "| Linux-node-b | refs/heads/main | 2.00 KB | 2048 |")
+}
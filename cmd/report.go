@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/actions/gh-actions-cache/internal"
+	"github.com/actions/gh-actions-cache/service"
+	"github.com/actions/gh-actions-cache/types"
+	ghTerm "github.com/cli/go-gh/pkg/term"
+	"github.com/spf13/cobra"
+)
+
+func NewCmdReport() *cobra.Command {
+	reportCommand := "report"
+
+	f := types.ReportOptions{}
+
+	var reportCmd = &cobra.Command{
+		Use:   "report",
+		Short: "Generates a Markdown or HTML report of the actions cache",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 0 {
+				return fmt.Errorf(fmt.Sprintf("Invalid argument(s). Expected 0 received %d", len(args)))
+			}
+
+			repo, err := internal.GetRepo(f.Repo)
+			if err != nil {
+				return err
+			}
+
+			// This will silence the usage (help) message as they are not needed for errors beyond this point
+			cmd.SilenceUsage = true
+
+			err = f.Validate()
+			if err != nil {
+				return err
+			}
+
+			artifactCache, err := service.NewArtifactCache(repo, reportCommand, VERSION)
+			if err != nil {
+				return types.HandledError{Message: err.Error(), InnerError: err}
+			}
+
+			usage, err := artifactCache.GetCacheUsage(cmd.Context())
+			if err != nil {
+				return internal.HttpErrorHandler(err, "The given repo does not exist.")
+			}
+
+			caches, err := getAllCaches(cmd.Context(), artifactCache)
+			if err != nil {
+				return internal.HttpErrorHandler(err, "The given repo does not exist.")
+			}
+
+			report := internal.BuildCacheReport(fmt.Sprintf("%s/%s", repo.Owner(), repo.Name()), usage, int64(f.QuotaGB)*internal.GB_IN_BYTES, caches, f.Top, time.Now())
+
+			var out io.Writer = ghTerm.FromEnv().Out()
+			var file *os.File
+			if f.Output != "" {
+				file, err = os.Create(f.Output)
+				if err != nil {
+					return types.HandledError{Message: fmt.Sprintf("Could not create report file '%s'", f.Output), InnerError: err}
+				}
+				defer file.Close()
+				out = file
+			}
+
+			if f.Format == "html" {
+				err = internal.RenderHTMLReport(out, report)
+			} else {
+				err = internal.RenderMarkdownReport(out, report)
+			}
+			if err == nil && file != nil {
+				err = file.Close()
+			}
+			if err != nil {
+				return types.HandledError{Message: "Could not write the report.", InnerError: err}
+			}
+			return nil
+		},
+	}
+
+	reportCmd.Flags().StringVarP(&f.Repo, "repo", "R", "", "Select another repository for finding actions cache.")
+	reportCmd.Flags().StringVarP(&f.Format, "format", "", "markdown", "Report format (markdown/html)")
+	reportCmd.Flags().StringVarP(&f.Output, "output", "o", "", "Write the report to a file instead of stdout")
+	reportCmd.Flags().IntVarP(&f.Top, "top", "", 10, "Number of entries shown in the largest and stalest sections")
+	reportCmd.Flags().IntVarP(&f.QuotaGB, "quota", "", internal.DEFAULT_QUOTA_IN_GB, "Cache storage quota of the repo in GB")
+	reportCmd.SetHelpTemplate(getReportHelp())
+
+	return reportCmd
+}
+
+func getReportHelp() string {
+	return `
+gh-actions-cache: Works with GitHub Actions Cache.
+
+USAGE:
+	gh actions-cache report [flags]
+
+ARGUMENTS:
+	No Arguments
+
+FLAGS:
+	-R, --repo <[HOST/]owner/repo>		Select another repository using the [HOST/]OWNER/REPO format
+	--format <string>			Report format (markdown/html). Default is markdown
+	-o, --output <file>			Write the report to a file instead of stdout
+	--top <int>				Number of entries shown in the largest and stalest sections (default is 10)
+	--quota <int>				Cache storage quota of the repo in GB (default is 10)
+
+INHERITED FLAGS
+	--help		Show help for command
+	--verbose	Log every request with its status and timing, the remaining API rate limit and the retries on stderr
+	--debug		Also log the request headers, the body of failed responses and the cause of errors, with tokens redacted. Also enabled by GH_DEBUG
+	--max-retry-wait <duration>	Longest total time to wait while retrying a rate limited or failed request (default is 1m)
+	--timeout <duration>		Abort the command if it runs longer than the given duration, e.g. 5m
+	--cache-ttl <duration>		Store listings and usage on disk and send conditional requests to revalidate them for that long, e.g. 1h (disabled by default)
+	--no-cache			Bypass the responses stored with --cache-ttl
+
+EXAMPLES:
+	$ gh actions-cache report >> $GITHUB_STEP_SUMMARY
+	$ gh actions-cache report --format html --output cache-report.html
+`
+}
@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/actions/gh-actions-cache/internal"
+	"github.com/actions/gh-actions-cache/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/h2non/gock.v1"
+)
+
+func TestReportWithIncorrectFormat(t *testing.T) {
+	t.Cleanup(gock.Off)
+
+	cmd := NewCmdReport()
+	cmd.SetArgs([]string{"--format", "pdf", "--repo", "testOrg/testRepo"})
+	err := cmd.Execute()
+
+	assert.ErrorContains(t, err, "pdf is not a valid value for format flag. Allowed values: markdown/html")
+	assert.True(t, gock.IsDone(), internal.PrintPendingMocks(gock.Pending()))
+}
+
+func TestReportSuccessWritesHTMLFile(t *testing.T) {
+	t.Cleanup(gock.Off)
+	gock.New("https://api.github.com").
+		Get("/repos/testOrg/testRepo/actions/cache/usage").
+		Reply(200).
+		JSON(`{
+			"full_name": "testOrg/testRepo",
+			"active_caches_size_in_bytes": 2432967,
+			"active_caches_count": 1
+		}`)
+
+	gock.New("https://api.github.com").
+		Get("/repos/testOrg/testRepo/actions/caches").
+		MatchParam("per_page", "100").
+		Reply(200).
+		JSON(`{
+			"total_count": 1,
+			"actions_caches": [
+				{
+					"id": 29,
+					"ref": "refs/heads/master",
+					"key": "Linux-node-3fd22dd3a926d576e2562e8b76a5ff157cd3b986f3d44195acfe7efa6bc05919",
+					"version": "7fcda33c1e1d849a13bcc06f49b9ab64efc01ca9dabe4d7a8d0d387feef4fc88",
+					"last_accessed_at": "2022-06-22T20:32:45.550000000Z",
+					"created_at": "2022-06-22T20:32:45.550000000Z",
+					"size_in_bytes": 2432967
+				}]
+			}`)
+
+	output := filepath.Join(t.TempDir(), "report.html")
+	cmd := NewCmdReport()
+	cmd.SetArgs([]string{"--repo", "testOrg/testRepo", "--format", "html", "--output", output})
+	err := cmd.Execute()
+
+	assert.NoError(t, err)
+	content, err := os.ReadFile(output)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "2.32 MB of 10.00 GB used")
+	assert.Contains(t, string(content), "Linux-node-3fd22dd3a926d576e2562e8b76a5ff157cd3b986f3d44195acfe7efa6bc05919")
+	assert.True(t, gock.IsDone(), internal.PrintPendingMocks(gock.Pending()))
+}
+
+func TestReportExitsAsInternalErrorWhenTheOutputFails(t *testing.T) {
+	t.Cleanup(gock.Off)
+	if _, err := os.Stat("/dev/full"); err != nil {
+		t.Skip("no /dev/full to simulate a full disk")
+	}
+	gock.New("https://api.github.com").
+		Get("/repos/testOrg/testRepo/actions/cache/usage").
+		Reply(200).
+		JSON(`{"full_name": "testOrg/testRepo", "active_caches_size_in_bytes": 0, "active_caches_count": 0}`)
+	gock.New("https://api.github.com").
+		Get("/repos/testOrg/testRepo/actions/caches").
+		MatchParam("per_page", "100").
+		Reply(200).
+		JSON(`{"total_count": 0, "actions_caches": []}`)
+
+	cmd := NewCmdReport()
+	cmd.SetArgs([]string{"--repo", "testOrg/testRepo", "--output", "/dev/full"})
+	err := cmd.Execute()
+
+	assert.ErrorContains(t, err, "Could not write the report.")
+	assert.Equal(t, types.EXIT_ERROR, types.ExitCode(err))
+	assert.True(t, gock.IsDone(), internal.PrintPendingMocks(gock.Pending()))
+}
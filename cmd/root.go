@@ -1,33 +1,122 @@
 package cmd
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
 	"os"
+	"os/signal"
+	"strings"
+	"time"
 
+	"github.com/actions/gh-actions-cache/service"
+	"github.com/actions/gh-actions-cache/types"
 	"github.com/spf13/cobra"
 )
 
 const VERSION = "1.0.1"
 
+var timeout time.Duration
+var cancelTimeout context.CancelFunc = func() {}
+
 var rootCmd = &cobra.Command{
 	Use:   "gh-actions-cache",
 	Short: "Works with GitHub Actions Cache. ",
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if !isConfigCommand(cmd) {
+			config, err := loadConfig()
+			if err == nil {
+				err = applyConfig(cmd, config)
+			}
+			if err != nil {
+				cmd.SilenceUsage = true
+				return err
+			}
+		}
+		if isDebugEnv(os.Getenv("GH_DEBUG")) {
+			service.HttpOptions.Debug = true
+		}
+		if timeout > 0 {
+			var ctx context.Context
+			ctx, cancelTimeout = context.WithTimeout(cmd.Context(), timeout)
+			cmd.SetContext(ctx)
+		}
+		return nil
+	},
 }
 
 func Execute() {
 	addCommandsToRoot()
-	err := rootCmd.Execute()
+	ctx, cancel := context.WithCancel(context.Background())
+	ctx = service.WithStop(ctx, handleInterrupts(cancel))
+	err := rootCmd.ExecuteContext(ctx)
+	cancelTimeout()
+	cancel()
 	if err != nil {
-		os.Exit(1)
+		logErrorCause(os.Stderr, err)
+		os.Exit(types.ExitCode(err))
+	}
+}
+
+// isDebugEnv tells whether GH_DEBUG enables debug logging, like it does for gh itself.
+func isDebugEnv(value string) bool {
+	switch strings.ToLower(value) {
+	case "", "0", "false", "no":
+		return false
 	}
+	return true
+}
+
+// logErrorCause prints the errors wrapped by a HandledError in verbose and debug mode, since its message alone
+// hides the cause.
+func logErrorCause(w io.Writer, err error) {
+	if !service.HttpOptions.Verbose && !service.HttpOptions.Debug {
+		return
+	}
+	var handledError types.HandledError
+	if errors.As(err, &handledError) && handledError.InnerError != nil {
+		fmt.Fprintf(w, "Caused by: %s\n", service.Redact(handledError.InnerError.Error()))
+	}
+}
+
+// handleInterrupts closes the returned channel on the first Ctrl-C so that running commands stop after their
+// requests in flight, and cancels them on the second one. Any further Ctrl-C terminates the process.
+func handleInterrupts(cancel context.CancelFunc) <-chan struct{} {
+	stop := make(chan struct{})
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	go func() {
+		<-interrupt
+		close(stop)
+		fmt.Fprintln(os.Stderr, "\nStopping after the requests in flight, press Ctrl-C again to abort them")
+		<-interrupt
+		signal.Stop(interrupt)
+		cancel()
+	}()
+	return stop
 }
 
 func init() {
+	rootCmd.PersistentFlags().BoolVar(&service.HttpOptions.Verbose, "verbose", false, "Log every request, the remaining API rate limit and the retries on stderr")
+	rootCmd.PersistentFlags().BoolVar(&service.HttpOptions.Debug, "debug", false, "Also log request headers, failed response bodies and the cause of errors")
+	rootCmd.PersistentFlags().DurationVar(&timeout, "timeout", 0, "Abort the command if it runs longer than the given duration")
+	rootCmd.PersistentFlags().DurationVar(&service.HttpOptions.MaxWait, "max-retry-wait", service.DEFAULT_MAX_RETRY_WAIT, "Longest total time to wait while retrying a request")
+	rootCmd.PersistentFlags().DurationVar(&service.ResponseCache.TTL, "cache-ttl", 0, "Store listings on disk and revalidate them with conditional requests for the given duration")
+	rootCmd.PersistentFlags().BoolVar(&service.ResponseCache.Disabled, "no-cache", false, "Bypass the responses stored with --cache-ttl")
 	rootCmd.SetHelpTemplate(getRootHelp())
 }
 
 func addCommandsToRoot() {
 	rootCmd.AddCommand(NewCmdList())
 	rootCmd.AddCommand(NewCmdDelete())
+	rootCmd.AddCommand(NewCmdReport())
+	rootCmd.AddCommand(NewCmdSnapshot())
+	rootCmd.AddCommand(NewCmdInteractive())
+	rootCmd.AddCommand(NewCmdConfig())
+	rootCmd.AddCommand(NewCmdAudit())
+	rootCmd.AddCommand(NewCmdPrune())
+	rootCmd.AddCommand(NewCmdApply())
 }
 
 func getRootHelp() string {
@@ -39,15 +128,40 @@ USAGE:
 
 CORE COMMANDS:
 	list:		list caches with result length cap of 100
-	delete:		delete caches with a key
+	delete:		delete caches with one or more keys
+	report:		generate a Markdown or HTML report of cache usage
+	snapshot:	save the cache listing to a file and diff it against a later one
+	interactive:	browse caches and delete a multi-selection (alias: browse)
+	config:		read and write the defaults of ~/.config/gh-actions-cache/config.yml and .github/actions-cache.yml
+	audit:		query the local log of deleted caches by date, repo or key
+	prune:		delete the caches matching a branch, key prefix or age, resumable from a journal
+	apply:		delete the caches of a plan written by prune --out that are unchanged since
 
 INHERITED FLAGS
 	--help		Show help for command
+	--verbose	Log every request with its status and timing, the remaining API rate limit and the retries on stderr
+	--debug		Also log the request headers, the body of failed responses and the cause of errors, with tokens redacted. Also enabled by GH_DEBUG
+	--max-retry-wait <duration>	Longest total time to wait while retrying a rate limited or failed request (default is 1m)
+	--timeout <duration>		Abort the command if it runs longer than the given duration, e.g. 5m
+	--cache-ttl <duration>		Store listings and usage on disk and send conditional requests to revalidate them for that long, e.g. 1h (disabled by default)
+	--no-cache			Bypass the responses stored with --cache-ttl
+
+EXIT CODES:
+	0	success
+	1	unexpected error
+	2	invalid arguments or flags
+	3	the repo or the caches were not found
+	4	permission denied or missing token scope
+	5	rate limited after retrying
+	6	some of the deletions failed
+	7	timed out, see --timeout
+	130	cancelled with Ctrl-C
 
 EXAMPLES:
 	$ gh actions-cache list
 	$ gh actions-cache list --limit 100
 	$ gh actions-cache list --order desc
 	$ gh actions-cache delete Linux-node-f5dbf39c9d11eba80242ac13
+	$ gh actions-cache report --format html --output cache-report.html
 `
 }
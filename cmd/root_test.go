@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/actions/gh-actions-cache/service"
+	"github.com/actions/gh-actions-cache/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsDebugEnv(t *testing.T) {
+	for _, value := range []string{"", "0", "false", "FALSE", "no"} {
+		assert.False(t, isDebugEnv(value), value)
+	}
+	for _, value := range []string{"1", "true", "api"} {
+		assert.True(t, isDebugEnv(value), value)
+	}
+}
+
+func TestLogErrorCause(t *testing.T) {
+	options := service.HttpOptions
+	t.Cleanup(func() { service.HttpOptions = options })
+	err := types.HandledError{Message: "We could not process your request.", InnerError: errors.New("HTTP 500: token ghp_secret123 rejected")}
+
+	var quiet bytes.Buffer
+	service.HttpOptions.Verbose = false
+	logErrorCause(&quiet, err)
+	assert.Empty(t, quiet.String())
+
+	var verbose bytes.Buffer
+	service.HttpOptions.Verbose = true
+	logErrorCause(&verbose, err)
+	assert.Equal(t, "Caused by: HTTP 500: token ghp_*** rejected\n", verbose.String())
+}
@@ -0,0 +1,196 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/actions/gh-actions-cache/internal"
+	"github.com/actions/gh-actions-cache/service"
+	"github.com/actions/gh-actions-cache/types"
+	ghRepo "github.com/cli/go-gh/pkg/repository"
+	ghTerm "github.com/cli/go-gh/pkg/term"
+	"github.com/spf13/cobra"
+)
+
+const LIVE_SNAPSHOT = "live"
+
+func NewCmdSnapshot() *cobra.Command {
+	var snapshotCmd = &cobra.Command{
+		Use:   "snapshot <command>",
+		Short: "Saves and compares snapshots of the actions cache",
+	}
+
+	snapshotCmd.AddCommand(newCmdSnapshotSave())
+	snapshotCmd.AddCommand(newCmdSnapshotDiff())
+	snapshotCmd.SetHelpTemplate(getSnapshotHelp())
+
+	return snapshotCmd
+}
+
+func newCmdSnapshotSave() *cobra.Command {
+	snapshotCommand := "snapshot"
+	var repoFlag string
+
+	var saveCmd = &cobra.Command{
+		Use:   "save <file>",
+		Short: "Saves the full cache listing as JSON",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return fmt.Errorf(fmt.Sprintf("accepts 1 arg(s), received %d", len(args)))
+			}
+
+			repo, err := internal.GetRepo(repoFlag)
+			if err != nil {
+				return err
+			}
+
+			// This will silence the usage (help) message as they are not needed for errors beyond this point
+			cmd.SilenceUsage = true
+
+			snapshot, err := takeSnapshot(cmd.Context(), repo, snapshotCommand)
+			if err != nil {
+				return err
+			}
+
+			err = internal.SaveSnapshot(args[0], snapshot)
+			if err != nil {
+				return types.HandledError{Message: fmt.Sprintf("Could not write snapshot file '%s'", args[0]), InnerError: err}
+			}
+
+			if ghTerm.FromEnv().IsTerminalOutput() {
+				fmt.Printf("Saved %s to %s\n", internal.PrintSingularOrPlural(len(snapshot.Caches), "cache entry", "cache entries"), args[0])
+			}
+			return nil
+		},
+	}
+
+	saveCmd.Flags().StringVarP(&repoFlag, "repo", "R", "", "Select another repository for finding actions cache.")
+	saveCmd.SetHelpTemplate(getSnapshotHelp())
+
+	return saveCmd
+}
+
+func newCmdSnapshotDiff() *cobra.Command {
+	snapshotCommand := "snapshot"
+	var repoFlag string
+
+	var diffCmd = &cobra.Command{
+		Use:   "diff <old> <new|live>",
+		Short: "Compares two snapshots or a snapshot with the live cache listing",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 2 {
+				return fmt.Errorf(fmt.Sprintf("accepts 2 arg(s), received %d", len(args)))
+			}
+
+			previous, err := internal.LoadSnapshot(args[0])
+			if err != nil {
+				return types.HandledError{Message: fmt.Sprintf("Could not read snapshot file '%s'", args[0]), InnerError: err}
+			}
+
+			var current types.CacheSnapshot
+			if args[1] == LIVE_SNAPSHOT {
+				if repoFlag == "" {
+					repoFlag = previous.Repo
+				}
+				repo, err := internal.GetRepo(repoFlag)
+				if err != nil {
+					return err
+				}
+
+				// This will silence the usage (help) message as they are not needed for errors beyond this point
+				cmd.SilenceUsage = true
+
+				if previous.Repo != "" && !strings.EqualFold(previous.Repo, snapshotRepo(repo)) {
+					return types.HandledError{Message: fmt.Sprintf("The snapshot '%s' is of %s, not of %s", args[0], previous.Repo, snapshotRepo(repo)), Kind: types.ErrorKindValidation}
+				}
+
+				current, err = takeSnapshot(cmd.Context(), repo, snapshotCommand)
+				if err != nil {
+					return err
+				}
+			} else {
+				cmd.SilenceUsage = true
+				current, err = internal.LoadSnapshot(args[1])
+				if err != nil {
+					return types.HandledError{Message: fmt.Sprintf("Could not read snapshot file '%s'", args[1]), InnerError: err}
+				}
+				if !strings.EqualFold(previous.Repo, current.Repo) {
+					fmt.Fprintf(os.Stderr, "Warning: comparing snapshots of different repositories, %s and %s\n", previous.Repo, current.Repo)
+				}
+			}
+
+			terminal := ghTerm.FromEnv()
+			if terminal.IsTerminalOutput() {
+				fmt.Printf("Comparing %s (%s) with %s (%s)\n\n", args[0], previous.TakenAt.Format(time.RFC3339), args[1], current.TakenAt.Format(time.RFC3339))
+			}
+			internal.PrintSnapshotDiff(terminal.Out(), internal.DiffSnapshots(previous.Caches, current.Caches))
+			return nil
+		},
+	}
+
+	diffCmd.Flags().StringVarP(&repoFlag, "repo", "R", "", "Select another repository when comparing with the live listing.")
+	_ = diffCmd.Flags().SetAnnotation("repo", NO_CONFIG_DEFAULT, []string{"true"})
+	diffCmd.SetHelpTemplate(getSnapshotHelp())
+
+	return diffCmd
+}
+
+func takeSnapshot(ctx context.Context, repo ghRepo.Repository, command string) (types.CacheSnapshot, error) {
+	artifactCache, err := service.NewArtifactCache(repo, command, VERSION)
+	if err != nil {
+		return types.CacheSnapshot{}, types.HandledError{Message: err.Error(), InnerError: err}
+	}
+
+	takenAt := time.Now().UTC()
+	caches, err := getAllCaches(ctx, artifactCache)
+	if err != nil {
+		return types.CacheSnapshot{}, internal.HttpErrorHandler(err, "The given repo does not exist.")
+	}
+
+	return types.CacheSnapshot{
+		Repo:       snapshotRepo(repo),
+		TakenAt:    takenAt,
+		TotalCount: len(caches),
+		Caches:     caches,
+	}, nil
+}
+
+// snapshotRepo is the HOST/OWNER/REPO recorded in the snapshots of repo.
+func snapshotRepo(repo ghRepo.Repository) string {
+	return fmt.Sprintf("%s/%s/%s", repo.Host(), repo.Owner(), repo.Name())
+}
+
+func getSnapshotHelp() string {
+	return `
+gh-actions-cache: Works with GitHub Actions Cache.
+
+USAGE:
+	gh actions-cache snapshot save <file> [flags]
+	gh actions-cache snapshot diff <old> <new|live> [flags]
+
+ARGUMENTS:
+	file		path of the JSON snapshot to write
+	old		path of the older snapshot
+	new|live	path of the newer snapshot, or "live" to compare with the current cache listing of the repository of old
+
+FLAGS:
+	-R, --repo <[HOST/]owner/repo>		Select another repository using the [HOST/]OWNER/REPO format. With diff, it must be the repository of old
+
+INHERITED FLAGS
+	--help		Show help for command
+	--verbose	Log every request with its status and timing, the remaining API rate limit and the retries on stderr
+	--debug		Also log the request headers, the body of failed responses and the cause of errors, with tokens redacted. Also enabled by GH_DEBUG
+	--max-retry-wait <duration>	Longest total time to wait while retrying a rate limited or failed request (default is 1m)
+	--timeout <duration>		Abort the command if it runs longer than the given duration, e.g. 5m
+	--cache-ttl <duration>		Store listings and usage on disk and send conditional requests to revalidate them for that long, e.g. 1h (disabled by default)
+	--no-cache			Bypass the responses stored with --cache-ttl
+
+EXAMPLES:
+	$ gh actions-cache snapshot save caches-monday.json
+	$ gh actions-cache snapshot diff caches-monday.json caches-tuesday.json
+	$ gh actions-cache snapshot diff caches-monday.json live
+`
+}
@@ -0,0 +1,120 @@
+package cmd
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/actions/gh-actions-cache/internal"
+	"github.com/actions/gh-actions-cache/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/h2non/gock.v1"
+)
+
+func TestSnapshotSaveWithIncorrectArguments(t *testing.T) {
+	t.Cleanup(gock.Off)
+
+	cmd := NewCmdSnapshot()
+	cmd.SetArgs([]string{"save"})
+	err := cmd.Execute()
+
+	assert.ErrorContains(t, err, "accepts 1 arg(s), received 0")
+	assert.True(t, gock.IsDone(), internal.PrintPendingMocks(gock.Pending()))
+}
+
+func TestSnapshotDiffWithMissingFile(t *testing.T) {
+	t.Cleanup(gock.Off)
+
+	cmd := NewCmdSnapshot()
+	cmd.SetArgs([]string{"diff", filepath.Join(t.TempDir(), "missing.json"), "live"})
+	err := cmd.Execute()
+
+	assert.ErrorContains(t, err, "Could not read snapshot file")
+	assert.True(t, gock.IsDone(), internal.PrintPendingMocks(gock.Pending()))
+}
+
+func TestSnapshotSaveAndDiffWithLive(t *testing.T) {
+	t.Cleanup(gock.Off)
+
+	gock.New("https://api.github.com").
+		Get("/repos/testOrg/testRepo/actions/caches").
+		MatchParam("per_page", "100").
+		Reply(200).
+		JSON(`{
+			"total_count": 1,
+			"actions_caches": [
+				{
+					"id": 29,
+					"ref": "refs/heads/master",
+					"key": "Linux-node-1",
+					"version": "7fcda33c1e1d849a13bcc06f49b9ab64efc01ca9dabe4d7a8d0d387feef4fc88",
+					"last_accessed_at": "2022-06-22T20:32:45.550000000Z",
+					"created_at": "2022-06-22T20:32:45.550000000Z",
+					"size_in_bytes": 2432967
+				}]
+			}`)
+
+	gock.New("https://api.github.com").
+		Get("/repos/testOrg/testRepo/actions/caches").
+		MatchParam("per_page", "100").
+		Reply(200).
+		JSON(`{
+			"total_count": 0,
+			"actions_caches": []
+			}`)
+
+	path := filepath.Join(t.TempDir(), "snapshot.json")
+	cmd := NewCmdSnapshot()
+	cmd.SetArgs([]string{"save", path, "--repo", "testOrg/testRepo"})
+	err := cmd.Execute()
+	require.NoError(t, err)
+
+	snapshot, err := internal.LoadSnapshot(path)
+	require.NoError(t, err)
+	assert.Equal(t, "github.com/testOrg/testRepo", snapshot.Repo)
+	assert.Len(t, snapshot.Caches, 1)
+
+	cmd = NewCmdSnapshot()
+	cmd.SetArgs([]string{"diff", path, "live"})
+	err = cmd.Execute()
+
+	assert.NoError(t, err)
+	assert.True(t, gock.IsDone(), internal.PrintPendingMocks(gock.Pending()))
+}
+
+func TestSnapshotDiffWithLiveListsTheRepositoryOfTheSnapshot(t *testing.T) {
+	t.Cleanup(gock.Off)
+	path := filepath.Join(t.TempDir(), "snapshot.json")
+	require.NoError(t, internal.SaveSnapshot(path, types.CacheSnapshot{Repo: "github.com/testOrg/testRepo", TakenAt: time.Now()}))
+
+	gock.New("https://api.github.com").
+		Get("/repos/testOrg/testRepo/actions/caches").
+		MatchParam("per_page", "100").
+		Reply(200).
+		JSON(`{"total_count": 0, "actions_caches": []}`)
+
+	cmd := NewCmdSnapshot()
+	cmd.SetArgs([]string{"diff", path, "live"})
+	diffCmd, _, err := cmd.Find([]string{"diff"})
+	require.NoError(t, err)
+	require.NoError(t, applyConfig(diffCmd, internal.LayeredConfig{Layers: []internal.ConfigLayer{{Config: types.Config{Repo: "otherOrg/otherRepo"}}}}))
+	err = cmd.Execute()
+
+	assert.NoError(t, err)
+	assert.True(t, gock.IsDone(), internal.PrintPendingMocks(gock.Pending()))
+}
+
+func TestSnapshotDiffWithLiveOfAnotherRepository(t *testing.T) {
+	t.Cleanup(gock.Off)
+	path := filepath.Join(t.TempDir(), "snapshot.json")
+	require.NoError(t, internal.SaveSnapshot(path, types.CacheSnapshot{Repo: "github.com/testOrg/testRepo", TakenAt: time.Now()}))
+
+	cmd := NewCmdSnapshot()
+	cmd.SetArgs([]string{"diff", path, "live", "--repo", "otherOrg/otherRepo"})
+	err := cmd.Execute()
+
+	assert.ErrorContains(t, err, "is of github.com/testOrg/testRepo, not of github.com/otherOrg/otherRepo")
+	assert.Equal(t, types.EXIT_VALIDATION, types.ExitCode(err))
+	assert.True(t, gock.IsDone(), internal.PrintPendingMocks(gock.Pending()))
+}
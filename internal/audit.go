@@ -0,0 +1,126 @@
+package internal
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/actions/gh-actions-cache/types"
+	ghTableprinter "github.com/cli/go-gh/pkg/tableprinter"
+	ghTerm "github.com/cli/go-gh/pkg/term"
+)
+
+// DefaultAuditLogPath is gh-actions-cache/audit.jsonl in $XDG_STATE_HOME, or in ~/.local/state when it is not set.
+func DefaultAuditLogPath() (string, error) {
+	dir, err := stateDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "audit.jsonl"), nil
+}
+
+func stateDir() (string, error) {
+	dir := os.Getenv("XDG_STATE_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		dir = filepath.Join(home, ".local", "state")
+	}
+	return filepath.Join(dir, "gh-actions-cache"), nil
+}
+
+// AppendAuditRecords appends the records to the JSON Lines file at path in a single write, so that concurrent
+// commands don't interleave their lines.
+func AppendAuditRecords(path string, records []types.AuditRecord) error {
+	var buffer bytes.Buffer
+	encoder := json.NewEncoder(&buffer)
+	for _, record := range records {
+		if err := encoder.Encode(record); err != nil {
+			return err
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	_, err = file.Write(buffer.Bytes())
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// CheckAuditLog creates the audit log at path when missing and checks that it can be appended to.
+func CheckAuditLog(path string) error {
+	return AppendAuditRecords(path, nil)
+}
+
+// ReadAuditRecords reads the records of a JSON Lines audit log matching filter.
+func ReadAuditRecords(r io.Reader, filter types.AuditFilter) ([]types.AuditRecord, error) {
+	var records []types.AuditRecord
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		var record types.AuditRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return nil, fmt.Errorf("invalid audit record on line %d: %w", line, err)
+		}
+		if MatchesAuditFilter(record, filter) {
+			records = append(records, record)
+		}
+	}
+	return records, scanner.Err()
+}
+
+func MatchesAuditFilter(record types.AuditRecord, filter types.AuditFilter) bool {
+	if !filter.Since.IsZero() && record.Time.Before(filter.Since) {
+		return false
+	}
+	if !filter.Until.IsZero() && !record.Time.Before(filter.Until) {
+		return false
+	}
+	if filter.Repo != "" {
+		repo := record.Repo
+		if strings.Count(filter.Repo, "/") > 1 {
+			repo = record.Host + "/" + record.Repo
+		}
+		if !strings.EqualFold(repo, filter.Repo) {
+			return false
+		}
+	}
+	return strings.HasPrefix(record.Key, filter.Key)
+}
+
+// PrettyPrintAuditRecords prints the records as a table on the terminal.
+func PrettyPrintAuditRecords(records []types.AuditRecord) {
+	terminal := ghTerm.FromEnv()
+	w, _, _ := terminal.Size()
+	tp := ghTableprinter.New(terminal.Out(), terminal.IsTerminalOutput(), w)
+
+	for _, record := range records {
+		tp.AddField(formatTime(record.Time))
+		tp.AddField(record.User)
+		tp.AddField(record.Repo)
+		tp.AddField(record.Key)
+		tp.AddField(record.Ref)
+		tp.AddField(FormatCacheSize(record.SizeInBytes))
+		tp.AddField(record.Reason)
+		tp.EndRow()
+	}
+
+	_ = tp.Render()
+}
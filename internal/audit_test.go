@@ -0,0 +1,46 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/actions/gh-actions-cache/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatchesAuditFilter(t *testing.T) {
+	record := types.AuditRecord{Time: time.Date(2024, 1, 31, 10, 0, 0, 0, time.UTC), Host: "ghe.example.com", Repo: "testOrg/testRepo", Key: "Linux-node-a"}
+
+	assert.True(t, MatchesAuditFilter(record, types.AuditFilter{}))
+	assert.True(t, MatchesAuditFilter(record, types.AuditFilter{Repo: "testOrg/testRepo"}))
+	assert.True(t, MatchesAuditFilter(record, types.AuditFilter{Repo: "ghe.example.com/testOrg/testRepo"}))
+	assert.False(t, MatchesAuditFilter(record, types.AuditFilter{Repo: "github.com/testOrg/testRepo"}))
+	assert.False(t, MatchesAuditFilter(record, types.AuditFilter{Key: "Windows-"}))
+	assert.False(t, MatchesAuditFilter(record, types.AuditFilter{Until: record.Time}))
+	assert.True(t, MatchesAuditFilter(record, types.AuditFilter{Since: record.Time}))
+}
+
+func TestAppendAuditRecords_AppendsLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", "audit.jsonl")
+
+	require.NoError(t, AppendAuditRecords(path, []types.AuditRecord{{CacheId: 1}}))
+	require.NoError(t, AppendAuditRecords(path, []types.AuditRecord{{CacheId: 2}, {CacheId: 3}}))
+
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	defer file.Close()
+	records, err := ReadAuditRecords(file, types.AuditFilter{})
+	require.NoError(t, err)
+	assert.Len(t, records, 3)
+	assert.Equal(t, int64(3), records[2].CacheId)
+}
+
+func TestReadAuditRecords_InvalidLine(t *testing.T) {
+	_, err := ReadAuditRecords(strings.NewReader("{\"cache_id\": 1}\nnot json\n"), types.AuditFilter{})
+
+	assert.ErrorContains(t, err, "invalid audit record on line 2")
+}
@@ -0,0 +1,253 @@
+package internal
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/AlecAivazis/survey/v2/terminal"
+	"github.com/TwiN/go-color"
+	"github.com/actions/gh-actions-cache/types"
+)
+
+type BrowserAction int
+
+const (
+	BrowserContinue BrowserAction = iota
+	BrowserQuit
+	BrowserDelete
+)
+
+const ENTER_ALT_SCREEN = "\033[?1049h\033[?25l"
+const EXIT_ALT_SCREEN = "\033[?25h\033[?1049l"
+
+var BROWSER_SORTS = []string{"last-used", "size", "created-at", "key"}
+
+// CacheBrowser holds the state of the interactive cache browser. It is independent of the terminal so that key
+// handling and rendering can be driven by the interactive command and by tests alike.
+type CacheBrowser struct {
+	Title      string
+	caches     []types.Cache
+	visible    []types.Cache
+	selected   map[int64]bool
+	cursor     int
+	offset     int
+	filter     string
+	filtering  bool
+	sortIndex  int
+	descending bool
+}
+
+func NewCacheBrowser(title string, caches []types.Cache) *CacheBrowser {
+	b := &CacheBrowser{Title: title, caches: caches, selected: map[int64]bool{}, descending: true}
+	b.refresh()
+	return b
+}
+
+func (b *CacheBrowser) HandleKey(key rune) BrowserAction {
+	if b.filtering {
+		switch key {
+		case terminal.KeyEnter, terminal.KeyEscape:
+			b.filtering = false
+		case terminal.KeyBackspace, terminal.KeyDelete:
+			if len(b.filter) > 0 {
+				_, size := utf8.DecodeLastRuneInString(b.filter)
+				b.filter = b.filter[:len(b.filter)-size]
+				b.refresh()
+			}
+		case terminal.KeyArrowUp, terminal.KeyArrowDown:
+			b.moveCursor(key)
+		case terminal.KeyInterrupt:
+			return BrowserQuit
+		default:
+			if key >= ' ' {
+				b.filter += string(key)
+				b.refresh()
+			}
+		}
+		return BrowserContinue
+	}
+
+	switch key {
+	case 'q', terminal.KeyEscape, terminal.KeyInterrupt:
+		return BrowserQuit
+	case terminal.KeyArrowUp, terminal.KeyArrowDown, 'k', 'j':
+		b.moveCursor(key)
+	case terminal.KeySpace:
+		if b.cursor < len(b.visible) {
+			id := b.visible[b.cursor].Id
+			b.selected[id] = !b.selected[id]
+			if !b.selected[id] {
+				delete(b.selected, id)
+			}
+		}
+	case 'a':
+		b.toggleAllVisible()
+	case '/':
+		b.filtering = true
+	case 's':
+		b.sortIndex = (b.sortIndex + 1) % len(BROWSER_SORTS)
+		b.refresh()
+	case 'r':
+		b.descending = !b.descending
+		b.refresh()
+	case 'd', terminal.KeyEnter:
+		if len(b.selected) > 0 {
+			return BrowserDelete
+		}
+	}
+	return BrowserContinue
+}
+
+// Selected returns the selected caches, including those hidden by the current filter.
+func (b *CacheBrowser) Selected() []types.Cache {
+	var selected []types.Cache
+	for _, cache := range b.caches {
+		if b.selected[cache.Id] {
+			selected = append(selected, cache)
+		}
+	}
+	return selected
+}
+
+func (b *CacheBrowser) Visible() []types.Cache {
+	return b.visible
+}
+
+func (b *CacheBrowser) SelectedSize() int64 {
+	return totalSize(b.Selected())
+}
+
+// Render draws the browser for a terminal of the given size. The output starts at the top left corner of the
+// screen and clears it first so it can be called repeatedly.
+func (b *CacheBrowser) Render(w io.Writer, width int, height int) {
+	rows := height - 6
+	if rows < 1 {
+		rows = 1
+	}
+	if b.cursor < b.offset {
+		b.offset = b.cursor
+	} else if b.cursor >= b.offset+rows {
+		b.offset = b.cursor - rows + 1
+	}
+
+	direction := "asc"
+	if b.descending {
+		direction = "desc"
+	}
+	var out strings.Builder
+	out.WriteString(CLEAR_SCREEN)
+	fmt.Fprintf(&out, "%s - %d of %d entries, sorted by %s (%s)\r\n", b.Title, len(b.visible), len(b.caches), BROWSER_SORTS[b.sortIndex], direction)
+	if b.filtering || b.filter != "" {
+		fmt.Fprintf(&out, "Filter: %s", b.filter)
+		if b.filtering {
+			out.WriteString("_")
+		}
+	}
+	out.WriteString("\r\n\r\n")
+
+	for i := b.offset; i < len(b.visible) && i < b.offset+rows; i++ {
+		cache := b.visible[i]
+		pointer, mark := " ", "[ ]"
+		if i == b.cursor {
+			pointer = ">"
+		}
+		if b.selected[cache.Id] {
+			mark = "[x]"
+		}
+		line := fmt.Sprintf("%s %s %-10s %-24s %-16s %s", pointer, mark, FormatCacheSize(cache.SizeInBytes), truncate(cache.Ref.Raw, 24), lastAccessedTime(cache.LastAccessedAt), cache.Key)
+		line = truncate(line, width)
+		if i == b.cursor {
+			line = color.Colorize(color.Bold, line)
+		}
+		out.WriteString(line + "\r\n")
+	}
+
+	fmt.Fprintf(&out, "\r\nSelected %s, %s\r\n", PrintSingularOrPlural(len(b.selected), "cache entry", "cache entries"), FormatCacheSize(b.SelectedSize()))
+	out.WriteString(truncate("up/down move, space select, a select all, / filter, s sort, r reverse, d delete selected, q quit", width))
+	_, _ = io.WriteString(w, out.String())
+}
+
+func (b *CacheBrowser) moveCursor(key rune) {
+	if key == terminal.KeyArrowUp || key == 'k' {
+		if b.cursor > 0 {
+			b.cursor--
+		}
+	} else if b.cursor < len(b.visible)-1 {
+		b.cursor++
+	}
+}
+
+func (b *CacheBrowser) toggleAllVisible() {
+	allSelected := len(b.visible) > 0
+	for _, cache := range b.visible {
+		if !b.selected[cache.Id] {
+			allSelected = false
+			break
+		}
+	}
+	for _, cache := range b.visible {
+		if allSelected {
+			delete(b.selected, cache.Id)
+		} else {
+			b.selected[cache.Id] = true
+		}
+	}
+}
+
+func (b *CacheBrowser) refresh() {
+	b.visible = b.visible[:0]
+	for _, cache := range b.caches {
+		if b.filter == "" || FuzzyMatch(b.filter, cache.Key) || FuzzyMatch(b.filter, cache.Ref.Raw) {
+			b.visible = append(b.visible, cache)
+		}
+	}
+
+	less := browserSortFuncs[BROWSER_SORTS[b.sortIndex]]
+	sort.SliceStable(b.visible, func(i, j int) bool {
+		if b.descending {
+			return less(b.visible[j], b.visible[i])
+		}
+		return less(b.visible[i], b.visible[j])
+	})
+
+	if b.cursor >= len(b.visible) {
+		b.cursor = len(b.visible) - 1
+	}
+	if b.cursor < 0 {
+		b.cursor = 0
+	}
+}
+
+var browserSortFuncs = map[string]func(a, b types.Cache) bool{
+	"last-used":  func(a, b types.Cache) bool { return a.LastAccessedAt.Before(b.LastAccessedAt) },
+	"size":       func(a, b types.Cache) bool { return a.SizeInBytes < b.SizeInBytes },
+	"created-at": func(a, b types.Cache) bool { return a.CreatedAt.Before(b.CreatedAt) },
+	"key":        func(a, b types.Cache) bool { return a.Key < b.Key },
+}
+
+// FuzzyMatch reports whether all runes of pattern appear in s in the same order, ignoring case.
+func FuzzyMatch(pattern string, s string) bool {
+	s = strings.ToLower(s)
+	for _, r := range strings.ToLower(pattern) {
+		i := strings.IndexRune(s, r)
+		if i < 0 {
+			return false
+		}
+		s = s[i+utf8.RuneLen(r):]
+	}
+	return true
+}
+
+func truncate(s string, width int) string {
+	if width <= 0 || utf8.RuneCountInString(s) <= width {
+		return s
+	}
+	runes := []rune(s)
+	if width <= 3 {
+		return string(runes[:width])
+	}
+	return string(runes[:width-3]) + "..."
+}
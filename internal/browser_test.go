@@ -0,0 +1,93 @@
+package internal
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/AlecAivazis/survey/v2/terminal"
+	"github.com/actions/gh-actions-cache/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func browserTestCaches() []types.Cache {
+	return []types.Cache{
+		{Id: 1, Ref: types.ParseRef("refs/heads/main"), Key: "Linux-node-aaa", LastAccessedAt: apiTime("2022-06-20T00:00:00Z"), SizeInBytes: 300},
+		{Id: 2, Ref: types.ParseRef("refs/pull/2/merge"), Key: "Linux-node-bbb", LastAccessedAt: apiTime("2022-06-01T00:00:00Z"), SizeInBytes: 100},
+		{Id: 3, Ref: types.ParseRef("refs/heads/main"), Key: "Windows-go-ccc", LastAccessedAt: apiTime("2022-06-10T00:00:00Z"), SizeInBytes: 200},
+	}
+}
+
+func visibleIds(b *CacheBrowser) []int64 {
+	var ids []int64
+	for _, cache := range b.Visible() {
+		ids = append(ids, cache.Id)
+	}
+	return ids
+}
+
+func TestCacheBrowser_SortsByLastUsedDescendingByDefault(t *testing.T) {
+	b := NewCacheBrowser("test", browserTestCaches())
+
+	assert.Equal(t, []int64{1, 3, 2}, visibleIds(b))
+
+	b.HandleKey('s')
+	assert.Equal(t, []int64{1, 3, 2}, visibleIds(b))
+	b.HandleKey('r')
+	assert.Equal(t, []int64{2, 3, 1}, visibleIds(b))
+}
+
+func TestCacheBrowser_FilterAndSelect(t *testing.T) {
+	b := NewCacheBrowser("test", browserTestCaches())
+
+	for _, key := range "/pull" {
+		b.HandleKey(key)
+	}
+	b.HandleKey(terminal.KeyEnter)
+	assert.Equal(t, []int64{2}, visibleIds(b))
+
+	b.HandleKey(terminal.KeySpace)
+	b.HandleKey('/')
+	for i := 0; i < 4; i++ {
+		b.HandleKey(terminal.KeyDelete)
+	}
+	b.HandleKey(terminal.KeyEnter)
+	assert.Equal(t, []int64{1, 3, 2}, visibleIds(b))
+
+	b.HandleKey(terminal.KeySpace)
+	assert.Equal(t, int64(400), b.SelectedSize())
+	if assert.Len(t, b.Selected(), 2) {
+		assert.Equal(t, int64(1), b.Selected()[0].Id)
+		assert.Equal(t, int64(2), b.Selected()[1].Id)
+	}
+}
+
+func TestCacheBrowser_Actions(t *testing.T) {
+	b := NewCacheBrowser("test", browserTestCaches())
+
+	assert.Equal(t, BrowserContinue, b.HandleKey('d'))
+	assert.Equal(t, BrowserContinue, b.HandleKey('a'))
+	assert.Len(t, b.Selected(), 3)
+	assert.Equal(t, BrowserDelete, b.HandleKey('d'))
+	assert.Equal(t, BrowserContinue, b.HandleKey('a'))
+	assert.Len(t, b.Selected(), 0)
+	assert.Equal(t, BrowserQuit, b.HandleKey('q'))
+}
+
+func TestCacheBrowser_RenderShowsRunningTotal(t *testing.T) {
+	b := NewCacheBrowser("Actions caches in testOrg/testRepo", browserTestCaches())
+	b.HandleKey(terminal.KeySpace)
+	b.HandleKey(terminal.KeyArrowDown)
+	b.HandleKey(terminal.KeySpace)
+
+	var out bytes.Buffer
+	b.Render(&out, 200, 20)
+
+	assert.Contains(t, out.String(), "Actions caches in testOrg/testRepo - 3 of 3 entries, sorted by last-used (desc)")
+	assert.Contains(t, out.String(), "Selected 2 cache entries, 500.00 B")
+}
+
+func TestFuzzyMatch(t *testing.T) {
+	assert.True(t, FuzzyMatch("lnx", "Linux-node"))
+	assert.True(t, FuzzyMatch("", "Linux-node"))
+	assert.False(t, FuzzyMatch("xnl", "Linux-node"))
+}
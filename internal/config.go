@@ -0,0 +1,213 @@
+package internal
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/actions/gh-actions-cache/types"
+	"gopkg.in/yaml.v3"
+)
+
+const CONFIG_ENV_PREFIX = "GH_ACTIONS_CACHE_"
+const REPO_CONFIG_FILE = ".github/actions-cache.yml"
+
+const (
+	CONFIG_ORIGIN_USER = "user"
+	CONFIG_ORIGIN_REPO = "repo"
+	CONFIG_ORIGIN_ENV  = "env"
+)
+
+// ConfigLayer is the configuration read from one origin.
+type ConfigLayer struct {
+	Origin string
+	// Path is the file the layer was read from, empty for the environment.
+	Path   string
+	Config types.Config
+}
+
+// LayeredConfig resolves every setting from the layer of highest precedence that sets it, except for the cumulative
+// settings that add up across the layers and the limits that take their lowest value.
+type LayeredConfig struct {
+	// Layers are ordered from the lowest to the highest precedence.
+	Layers []ConfigLayer
+}
+
+// UserConfigPath is gh-actions-cache/config.yml in $XDG_CONFIG_HOME, or in ~/.config when it is not set.
+func UserConfigPath() (string, error) {
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, "gh-actions-cache", "config.yml"), nil
+}
+
+// RepoConfigPath returns the .github/actions-cache.yml file of the repository checked out in dir or in one of its
+// parents. Outside of a repository it is the file in dir.
+func RepoConfigPath(dir string) string {
+	for current := dir; ; {
+		if _, err := os.Stat(filepath.Join(current, ".git")); err == nil {
+			return filepath.Join(current, filepath.FromSlash(REPO_CONFIG_FILE))
+		}
+		parent := filepath.Dir(current)
+		if parent == current {
+			return filepath.Join(dir, filepath.FromSlash(REPO_CONFIG_FILE))
+		}
+		current = parent
+	}
+}
+
+// LoadConfig reads the user file, the file of the repository checked out in dir and the GH_ACTIONS_CACHE_*
+// variables, in increasing precedence.
+func LoadConfig(dir string, getenv func(string) string) (LayeredConfig, error) {
+	userPath, err := UserConfigPath()
+	if err != nil {
+		return LayeredConfig{}, err
+	}
+	userConfig, err := ReadConfigFile(userPath)
+	if err != nil {
+		return LayeredConfig{}, err
+	}
+	repoPath := RepoConfigPath(dir)
+	repoConfig, err := ReadConfigFile(repoPath)
+	if err != nil {
+		return LayeredConfig{}, err
+	}
+	envConfig, err := EnvConfig(getenv)
+	if err != nil {
+		return LayeredConfig{}, err
+	}
+	return LayeredConfig{Layers: []ConfigLayer{
+		{Origin: CONFIG_ORIGIN_USER, Path: userPath, Config: userConfig},
+		{Origin: CONFIG_ORIGIN_REPO, Path: repoPath, Config: repoConfig},
+		{Origin: CONFIG_ORIGIN_ENV, Config: envConfig},
+	}}, nil
+}
+
+// Get returns the value of a setting and the origin of the layer it comes from, or empty strings when no layer sets
+// it. The repository layer is skipped for the settings that only the user may set. The values of the cumulative
+// settings are combined across the layers without duplicates, with the origins of all the layers setting them, and
+// the limits are the lowest value of the layers.
+func (c LayeredConfig) Get(key types.ConfigKey) (string, string) {
+	if key.Cumulative {
+		return c.combine(key)
+	}
+	if key.IsLimit() {
+		return c.lowest(key)
+	}
+	for i := len(c.Layers) - 1; i >= 0; i-- {
+		if c.ignores(key, c.Layers[i]) {
+			continue
+		}
+		if value := key.Get(&c.Layers[i].Config); value != "" {
+			return value, c.Layers[i].Origin
+		}
+	}
+	return "", ""
+}
+
+func (c LayeredConfig) combine(key types.ConfigKey) (string, string) {
+	var values, origins []string
+	seen := map[string]bool{}
+	for _, layer := range c.Layers {
+		if c.ignores(key, layer) {
+			continue
+		}
+		items := types.SplitConfigList(key.Get(&layer.Config))
+		if len(items) == 0 {
+			continue
+		}
+		origins = append(origins, layer.Origin)
+		for _, item := range items {
+			if !seen[item] {
+				seen[item] = true
+				values = append(values, item)
+			}
+		}
+	}
+	return strings.Join(values, ","), strings.Join(origins, ",")
+}
+
+func (c LayeredConfig) lowest(key types.ConfigKey) (string, string) {
+	value, origin, lowest := "", "", 0.0
+	for i := range c.Layers {
+		layer := &c.Layers[i]
+		if c.ignores(key, *layer) || key.Get(&layer.Config) == "" {
+			continue
+		}
+		if limit := key.Limit(&layer.Config); value == "" || limit < lowest {
+			value, origin, lowest = key.Get(&layer.Config), layer.Origin, limit
+		}
+	}
+	return value, origin
+}
+
+func (c LayeredConfig) ignores(key types.ConfigKey, layer ConfigLayer) bool {
+	return key.UserOnly && layer.Origin == CONFIG_ORIGIN_REPO
+}
+
+// ReadConfigFile reads a configuration file, a missing file being an empty configuration. Unknown keys are
+// rejected so that typos don't go unnoticed.
+func ReadConfigFile(path string) (types.Config, error) {
+	var config types.Config
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return config, nil
+	}
+	if err != nil {
+		return config, err
+	}
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&config); err != nil && err != io.EOF {
+		return config, fmt.Errorf("invalid configuration file %s: %w", path, err)
+	}
+	if err := config.Validate(); err != nil {
+		return config, fmt.Errorf("invalid configuration file %s: %w", path, err)
+	}
+	return config, nil
+}
+
+func WriteConfigFile(path string, config types.Config) error {
+	data, err := yaml.Marshal(config)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// ConfigEnvName is the variable of a setting, e.g. GH_ACTIONS_CACHE_TIME_FORMAT for time-format.
+func ConfigEnvName(key types.ConfigKey) string {
+	return CONFIG_ENV_PREFIX + strings.ToUpper(strings.ReplaceAll(key.Name, "-", "_"))
+}
+
+// EnvConfig reads the settings from the GH_ACTIONS_CACHE_* variables. GH_REPO sets the repo like it does for gh.
+func EnvConfig(getenv func(string) string) (types.Config, error) {
+	var config types.Config
+	for _, key := range types.CONFIG_KEYS {
+		name := ConfigEnvName(key)
+		value := getenv(name)
+		if value == "" && key.Name == "repo" {
+			name, value = "GH_REPO", getenv("GH_REPO")
+		}
+		if value == "" {
+			continue
+		}
+		if err := key.Set(&config, value); err != nil {
+			return config, fmt.Errorf("invalid %s: %w", name, err)
+		}
+	}
+	return config, nil
+}
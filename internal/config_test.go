@@ -0,0 +1,151 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/actions/gh-actions-cache/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeFile(t *testing.T, path string, content string) {
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+}
+
+func configKey(t *testing.T, name string) types.ConfigKey {
+	key, err := types.FindConfigKey(name)
+	require.NoError(t, err)
+	return key
+}
+
+func TestLoadConfig_Precedence(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", home)
+	writeFile(t, filepath.Join(home, "gh-actions-cache", "config.yml"), "repo: user/repo\nlimit: 50\ncolumns: [key, size]\n")
+	checkout := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(checkout, ".git"), 0o755))
+	writeFile(t, filepath.Join(checkout, ".github", "actions-cache.yml"), "limit: 20\nsort: size\n")
+	env := map[string]string{"GH_ACTIONS_CACHE_SORT": "created-at"}
+
+	config, err := LoadConfig(filepath.Join(checkout, "src", "app"), func(name string) string { return env[name] })
+	require.NoError(t, err)
+
+	value, origin := config.Get(configKey(t, "repo"))
+	assert.Equal(t, []string{"user/repo", CONFIG_ORIGIN_USER}, []string{value, origin})
+	value, origin = config.Get(configKey(t, "limit"))
+	assert.Equal(t, []string{"20", CONFIG_ORIGIN_REPO}, []string{value, origin})
+	value, origin = config.Get(configKey(t, "sort"))
+	assert.Equal(t, []string{"created-at", CONFIG_ORIGIN_ENV}, []string{value, origin})
+	value, _ = config.Get(configKey(t, "columns"))
+	assert.Equal(t, "key,size", value)
+	value, origin = config.Get(configKey(t, "order"))
+	assert.Equal(t, []string{"", ""}, []string{value, origin})
+}
+
+func TestEnvConfig_FallsBackToGhRepo(t *testing.T) {
+	env := map[string]string{"GH_REPO": "gh/repo"}
+
+	config, err := EnvConfig(func(name string) string { return env[name] })
+
+	require.NoError(t, err)
+	assert.Equal(t, "gh/repo", config.Repo)
+}
+
+func TestEnvConfig_InvalidValue(t *testing.T) {
+	env := map[string]string{"GH_ACTIONS_CACHE_TIME_FORMAT": "iso"}
+
+	_, err := EnvConfig(func(name string) string { return env[name] })
+
+	assert.ErrorContains(t, err, "invalid GH_ACTIONS_CACHE_TIME_FORMAT: iso is not a valid value for time-format")
+}
+
+func TestReadConfigFile_RejectsUnknownAndInvalidSettings(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yml")
+
+	writeFile(t, path, "colour: red\n")
+	_, err := ReadConfigFile(path)
+	assert.ErrorContains(t, err, "field colour not found")
+
+	writeFile(t, path, "order: up\n")
+	_, err = ReadConfigFile(path)
+	assert.ErrorContains(t, err, "up is not a valid value for order")
+}
+
+func TestWriteConfigFile_RoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "gh-actions-cache", "config.yml")
+	config := types.Config{Repo: "testOrg/testRepo", ProtectedRefs: []string{"refs/heads/main"}}
+
+	require.NoError(t, WriteConfigFile(path, config))
+	read, err := ReadConfigFile(path)
+
+	require.NoError(t, err)
+	assert.Equal(t, config, read)
+}
+
+func TestRepoConfigPath_OutsideOfARepository(t *testing.T) {
+	dir := t.TempDir()
+
+	assert.Equal(t, filepath.Join(dir, ".github", "actions-cache.yml"), RepoConfigPath(dir))
+}
+
+func TestLoadConfig_IgnoresUserOnlySettingsOfTheRepositoryFile(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	checkout := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(checkout, ".git"), 0o755))
+	writeFile(t, filepath.Join(checkout, ".github", "actions-cache.yml"), "audit-log: /dev/null\nlimit: 20\n")
+
+	config, err := LoadConfig(checkout, func(string) string { return "" })
+	require.NoError(t, err)
+
+	value, origin := config.Get(configKey(t, "audit-log"))
+	assert.Equal(t, []string{"", ""}, []string{value, origin})
+	value, _ = config.Get(configKey(t, "limit"))
+	assert.Equal(t, "20", value)
+
+	env := map[string]string{"GH_ACTIONS_CACHE_AUDIT_LOG": "/var/log/caches.jsonl"}
+	config, err = LoadConfig(checkout, func(name string) string { return env[name] })
+	require.NoError(t, err)
+	value, origin = config.Get(configKey(t, "audit-log"))
+	assert.Equal(t, []string{"/var/log/caches.jsonl", CONFIG_ORIGIN_ENV}, []string{value, origin})
+}
+
+func TestLoadConfig_CombinesCumulativeSettingsAcrossTheLayers(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", home)
+	writeFile(t, filepath.Join(home, "gh-actions-cache", "config.yml"), "protected-refs: [main, develop]\n")
+	checkout := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(checkout, ".git"), 0o755))
+	writeFile(t, filepath.Join(checkout, ".github", "actions-cache.yml"), "protected-refs: [release, main]\n")
+	env := map[string]string{"GH_ACTIONS_CACHE_PROTECTED_REFS": "hotfix"}
+
+	config, err := LoadConfig(checkout, func(name string) string { return env[name] })
+	require.NoError(t, err)
+
+	value, origin := config.Get(configKey(t, "protected-refs"))
+	assert.Equal(t, []string{"main,develop,release,hotfix", "user,repo,env"}, []string{value, origin})
+	value, origin = config.Get(configKey(t, "protected-key-prefixes"))
+	assert.Equal(t, []string{"", ""}, []string{value, origin})
+}
+
+func TestLoadConfig_TakesTheLowestLimitOfTheLayers(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", home)
+	writeFile(t, filepath.Join(home, "gh-actions-cache", "config.yml"), "max-delete-entries: 10\nmax-delete-size: 1GB\n")
+	checkout := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(checkout, ".git"), 0o755))
+	writeFile(t, filepath.Join(checkout, ".github", "actions-cache.yml"), "max-delete-entries: 100000\nmax-delete-size: 500MB\nmax-delete-usage-percent: 50\n")
+	env := map[string]string{"GH_ACTIONS_CACHE_MAX_DELETE_USAGE_PERCENT": "80"}
+
+	config, err := LoadConfig(checkout, func(name string) string { return env[name] })
+	require.NoError(t, err)
+
+	value, origin := config.Get(configKey(t, "max-delete-entries"))
+	assert.Equal(t, []string{"10", CONFIG_ORIGIN_USER}, []string{value, origin})
+	value, origin = config.Get(configKey(t, "max-delete-size"))
+	assert.Equal(t, []string{"500MB", CONFIG_ORIGIN_REPO}, []string{value, origin})
+	value, origin = config.Get(configKey(t, "max-delete-usage-percent"))
+	assert.Equal(t, []string{"50", CONFIG_ORIGIN_REPO}, []string{value, origin})
+}
@@ -0,0 +1,76 @@
+package internal
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+
+	"github.com/actions/gh-actions-cache/types"
+)
+
+var CACHE_EXPORT_HEADER = []string{"id", "key", "ref", "version", "size_in_bytes", "created_at", "last_accessed_at"}
+
+// WriteDelimitedCacheList writes the caches as delimited records (CSV when comma is ',' and TSV when it is '\t').
+// The header row is always written so that consumers can rely on the column order even for an empty listing.
+func WriteDelimitedCacheList(w io.Writer, caches []types.Cache, comma rune) error {
+	writer, err := NewDelimitedCacheWriter(w, comma)
+	if err != nil {
+		return err
+	}
+	for _, cache := range caches {
+		if err := writer.Write(cache); err != nil {
+			return err
+		}
+	}
+	return writer.Flush()
+}
+
+// DelimitedCacheWriter writes caches as delimited records one at a time, e.g. while the pages of a listing arrive.
+type DelimitedCacheWriter struct {
+	writer *csv.Writer
+}
+
+// NewDelimitedCacheWriter writes the header row and returns a writer for the records.
+func NewDelimitedCacheWriter(w io.Writer, comma rune) (*DelimitedCacheWriter, error) {
+	writer := csv.NewWriter(w)
+	writer.Comma = comma
+	if err := writer.Write(CACHE_EXPORT_HEADER); err != nil {
+		return nil, err
+	}
+	return &DelimitedCacheWriter{writer: writer}, nil
+}
+
+func (d *DelimitedCacheWriter) Write(cache types.Cache) error {
+	return d.writer.Write([]string{
+		strconv.FormatInt(cache.Id, 10),
+		cache.Key,
+		cache.Ref.Raw,
+		cache.Version,
+		strconv.FormatInt(cache.SizeInBytes, 10),
+		types.FormatApiTime(cache.CreatedAt),
+		types.FormatApiTime(cache.LastAccessedAt),
+	})
+}
+
+func (d *DelimitedCacheWriter) Flush() error {
+	d.writer.Flush()
+	return d.writer.Error()
+}
+
+func FormatDelimiter(format string) rune {
+	if format == "tsv" {
+		return '\t'
+	}
+	return ','
+}
+
+// WriteJSONCacheList writes the caches as a JSON array using the field names of the API.
+func WriteJSONCacheList(w io.Writer, caches []types.Cache) error {
+	if caches == nil {
+		caches = []types.Cache{}
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(caches)
+}
@@ -0,0 +1,37 @@
+package internal
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/actions/gh-actions-cache/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteDelimitedCacheList_CSV(t *testing.T) {
+	caches := []types.Cache{
+		{
+			Id:             29,
+			Ref:            types.ParseRef("refs/heads/main"),
+			Key:            "Linux-node-a,b",
+			Version:        "7fcda33c",
+			LastAccessedAt: apiTime("2022-06-22T20:32:45.550000000Z"),
+			CreatedAt:      apiTime("2022-06-21T10:00:00.000000000Z"),
+			SizeInBytes:    2432967,
+		},
+	}
+	var out bytes.Buffer
+	err := WriteDelimitedCacheList(&out, caches, FormatDelimiter("csv"))
+
+	assert.NoError(t, err)
+	assert.Equal(t, "id,key,ref,version,size_in_bytes,created_at,last_accessed_at\n"+
+		"29,\"Linux-node-a,b\",refs/heads/main,7fcda33c,2432967,2022-06-21T10:00:00Z,2022-06-22T20:32:45.55Z\n", out.String())
+}
+
+func TestWriteDelimitedCacheList_TSVWithNoCaches(t *testing.T) {
+	var out bytes.Buffer
+	err := WriteDelimitedCacheList(&out, nil, FormatDelimiter("tsv"))
+
+	assert.NoError(t, err)
+	assert.Equal(t, "id\tkey\tref\tversion\tsize_in_bytes\tcreated_at\tlast_accessed_at\n", out.String())
+}
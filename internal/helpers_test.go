@@ -0,0 +1,12 @@
+package internal
+
+import "time"
+
+// apiTime parses a timestamp in the format of the API for test fixtures.
+func apiTime(value string) time.Time {
+	t, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
@@ -0,0 +1,120 @@
+package internal
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/actions/gh-actions-cache/types"
+)
+
+// JournalWriter appends the finished deletions to a deletion journal.
+type JournalWriter struct {
+	Path string
+	file *os.File
+}
+
+// DefaultJournalPath is a new journal for repo, OWNER/REPO or HOST/OWNER/REPO, in the journals directory of
+// $XDG_STATE_HOME/gh-actions-cache, or of ~/.local/state/gh-actions-cache when it is not set.
+func DefaultJournalPath(repo string, now time.Time) (string, error) {
+	dir, err := stateDir()
+	if err != nil {
+		return "", err
+	}
+	name := fmt.Sprintf("%s-%s.jsonl", strings.ReplaceAll(repo, "/", "-"), now.UTC().Format("20060102T150405Z"))
+	return filepath.Join(dir, "journals", name), nil
+}
+
+// CreateJournal writes a new journal at path with the plan on its first line.
+func CreateJournal(path string, plan types.JournalPlan) (*JournalWriter, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, err
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	journal := &JournalWriter{Path: path, file: file}
+	if err := journal.write(types.JournalRecord{Plan: &plan}); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return journal, nil
+}
+
+// OpenJournal appends to the existing journal at path, to resume its deletions.
+func OpenJournal(path string) (*JournalWriter, error) {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	return &JournalWriter{Path: path, file: file}, nil
+}
+
+// Record marks the deletion of a cache as finished with status types.JOURNAL_DELETED or types.JOURNAL_GONE. Every
+// line is synced to disk so that it survives the process being killed.
+func (j *JournalWriter) Record(cacheId int64, status string) error {
+	return j.write(types.JournalRecord{CacheId: cacheId, Status: status})
+}
+
+func (j *JournalWriter) write(record types.JournalRecord) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	if _, err := j.file.Write(append(line, '\n')); err != nil {
+		return err
+	}
+	return j.file.Sync()
+}
+
+func (j *JournalWriter) Close() error {
+	return j.file.Close()
+}
+
+// ReadJournal reads a deletion journal. A last line cut short, by a crash while it was written, is ignored since
+// that deletion is attempted again.
+func ReadJournal(r io.Reader) (types.DeletionJournal, error) {
+	journal := types.DeletionJournal{Completed: map[int64]string{}}
+	reader := bufio.NewReader(r)
+	planned := false
+	for line := 1; ; line++ {
+		data, readErr := reader.ReadBytes('\n')
+		if readErr != nil && readErr != io.EOF {
+			return journal, readErr
+		}
+		complete := readErr == nil
+		if len(bytes.TrimSpace(data)) > 0 {
+			var record types.JournalRecord
+			if err := json.Unmarshal(data, &record); err != nil {
+				if !complete && planned {
+					break
+				}
+				return journal, fmt.Errorf("invalid journal record on line %d: %w", line, err)
+			}
+			switch {
+			case record.Plan != nil && !planned:
+				journal.Plan, planned = *record.Plan, true
+			case !planned:
+				return journal, fmt.Errorf("the journal does not start with a plan")
+			case record.Status == types.JOURNAL_DELETED || record.Status == types.JOURNAL_GONE:
+				journal.Completed[record.CacheId] = record.Status
+			default:
+				return journal, fmt.Errorf("invalid journal record on line %d", line)
+			}
+		}
+		if !complete {
+			break
+		}
+	}
+	if !planned {
+		return journal, fmt.Errorf("the journal does not start with a plan")
+	}
+	return journal, nil
+}
@@ -0,0 +1,68 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/actions/gh-actions-cache/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJournalRecordsFinishedDeletions(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journals", "prune.jsonl")
+	plan := types.JournalPlan{
+		Repo:      "github.com/testOrg/testRepo",
+		CreatedAt: time.Date(2024, 1, 31, 10, 0, 0, 0, time.UTC),
+		Caches:    []types.Cache{{Id: 1, Key: "keyOne"}, {Id: 2, Key: "keyTwo"}, {Id: 3, Key: "keyThree"}},
+	}
+
+	writer, err := CreateJournal(path, plan)
+	require.NoError(t, err)
+	require.NoError(t, writer.Record(1, types.JOURNAL_DELETED))
+	require.NoError(t, writer.Close())
+
+	writer, err = OpenJournal(path)
+	require.NoError(t, err)
+	require.NoError(t, writer.Record(3, types.JOURNAL_GONE))
+	require.NoError(t, writer.Close())
+
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	defer file.Close()
+	journal, err := ReadJournal(file)
+	require.NoError(t, err)
+	assert.Equal(t, "github.com/testOrg/testRepo", journal.Plan.Repo)
+	assert.Equal(t, map[int64]string{1: types.JOURNAL_DELETED, 3: types.JOURNAL_GONE}, journal.Completed)
+	if remaining := journal.Remaining(); assert.Len(t, remaining, 1) {
+		assert.Equal(t, "keyTwo", remaining[0].Key)
+	}
+}
+
+func TestReadJournalIgnoresATruncatedLastLine(t *testing.T) {
+	journal, err := ReadJournal(strings.NewReader(`{"plan": {"repo": "github.com/testOrg/testRepo", "actions_caches": [{"id": 1}, {"id": 2}]}}
+{"cache_id": 1, "status": "deleted"}
+{"cache_id": 2, "sta`))
+
+	require.NoError(t, err)
+	assert.Equal(t, map[int64]string{1: types.JOURNAL_DELETED}, journal.Completed)
+	assert.Len(t, journal.Remaining(), 1)
+}
+
+func TestReadJournalWithoutPlan(t *testing.T) {
+	_, err := ReadJournal(strings.NewReader(`{"cache_id": 1, "status": "deleted"}` + "\n"))
+
+	assert.ErrorContains(t, err, "the journal does not start with a plan")
+}
+
+func TestDefaultJournalPath(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", "/state")
+
+	path, err := DefaultJournalPath("github.com/testOrg/testRepo", time.Date(2024, 1, 31, 10, 0, 0, 0, time.UTC))
+
+	require.NoError(t, err)
+	assert.Equal(t, "/state/gh-actions-cache/journals/github.com-testOrg-testRepo-20240131T100000Z.jsonl", path)
+}
@@ -0,0 +1,67 @@
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/actions/gh-actions-cache/types"
+)
+
+// PlanChange is a planned cache that apply skips, with the reason why.
+type PlanChange struct {
+	Entry  types.PlanEntry
+	Reason string
+}
+
+func SavePlan(path string, plan types.CleanupPlan) error {
+	if plan.Entries == nil {
+		plan.Entries = []types.PlanEntry{}
+	}
+	data, err := json.MarshalIndent(plan, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+func LoadPlan(path string) (types.CleanupPlan, error) {
+	var plan types.CleanupPlan
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return plan, err
+	}
+	if err := json.Unmarshal(data, &plan); err != nil {
+		return plan, err
+	}
+	if plan.Repo == "" {
+		return plan, fmt.Errorf("the plan has no repo")
+	}
+	return plan, nil
+}
+
+// VerifyPlan matches the planned entries with the current caches by id. It returns the current caches that are
+// unchanged since the plan, and the entries that no longer exist, were used since or were replaced.
+func VerifyPlan(plan types.CleanupPlan, current []types.Cache) ([]types.Cache, []PlanChange) {
+	currentById := map[int64]types.Cache{}
+	for _, cache := range current {
+		currentById[cache.Id] = cache
+	}
+
+	var unchanged []types.Cache
+	var changes []PlanChange
+	for _, entry := range plan.Entries {
+		cache, ok := currentById[entry.Id]
+		switch {
+		case !ok:
+			changes = append(changes, PlanChange{Entry: entry, Reason: "no longer exists"})
+		case cache.Key != entry.Key || cache.Ref.Raw != entry.Ref || cache.Version != entry.Version || cache.SizeInBytes != entry.SizeInBytes:
+			changes = append(changes, PlanChange{Entry: entry, Reason: "changed since the plan"})
+		case !cache.LastAccessedAt.Equal(entry.LastAccessedAt):
+			changes = append(changes, PlanChange{Entry: entry, Reason: fmt.Sprintf("used since the plan, %s", formatTime(cache.LastAccessedAt))})
+		default:
+			unchanged = append(unchanged, cache)
+		}
+	}
+	return unchanged, changes
+}
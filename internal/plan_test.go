@@ -0,0 +1,54 @@
+package internal
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/actions/gh-actions-cache/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifyPlan(t *testing.T) {
+	lastUsed := time.Date(2024, 1, 31, 10, 0, 0, 0, time.UTC)
+	planned := func(id int64, key string) types.Cache {
+		return types.Cache{Id: id, Key: key, Ref: types.ParseRef("refs/heads/main"), Version: "v1", SizeInBytes: 100, LastAccessedAt: lastUsed}
+	}
+	plan := types.CleanupPlan{Repo: "github.com/testOrg/testRepo"}
+	for _, cache := range []types.Cache{planned(1, "unchanged"), planned(2, "used"), planned(3, "replaced"), planned(4, "gone")} {
+		plan.Entries = append(plan.Entries, types.NewPlanEntry(cache))
+	}
+
+	used := planned(2, "used")
+	used.LastAccessedAt = lastUsed.Add(time.Hour)
+	replaced := planned(3, "replaced")
+	replaced.Version = "v2"
+	unchanged, changes := VerifyPlan(plan, []types.Cache{planned(1, "unchanged"), used, replaced, planned(5, "new")})
+
+	if assert.Len(t, unchanged, 1) {
+		assert.Equal(t, int64(1), unchanged[0].Id)
+	}
+	reasons := map[int64]string{}
+	for _, change := range changes {
+		reasons[change.Entry.Id] = change.Reason
+	}
+	assert.Contains(t, reasons[2], "used since the plan")
+	assert.Equal(t, "changed since the plan", reasons[3])
+	assert.Equal(t, "no longer exists", reasons[4])
+}
+
+func TestSaveAndLoadPlan(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "plan.json")
+	plan := types.CleanupPlan{
+		Repo:      "github.com/testOrg/testRepo",
+		CreatedAt: time.Date(2024, 1, 31, 10, 0, 0, 0, time.UTC),
+		Entries:   []types.PlanEntry{{Id: 1, Key: "keyOne", Ref: "refs/heads/main", Version: "v1", SizeInBytes: 100, LastAccessedAt: time.Date(2024, 1, 30, 10, 0, 0, 280000000, time.UTC)}},
+	}
+
+	require.NoError(t, SavePlan(path, plan))
+	loaded, err := LoadPlan(path)
+
+	require.NoError(t, err)
+	assert.Equal(t, plan, loaded)
+}
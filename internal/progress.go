@@ -0,0 +1,30 @@
+package internal
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+const PROGRESS_BAR_WIDTH = 30
+
+type ProgressBar struct {
+	out   io.Writer
+	label string
+}
+
+func NewProgressBar(out io.Writer, label string) *ProgressBar {
+	return &ProgressBar{out: out, label: label}
+}
+
+// Update redraws the bar on the current line. The line is terminated once done reaches total.
+func (p *ProgressBar) Update(done int, total int) {
+	if total <= 0 {
+		return
+	}
+	filled := PROGRESS_BAR_WIDTH * done / total
+	fmt.Fprintf(p.out, "\r%s [%s%s] %d/%d", p.label, strings.Repeat("#", filled), strings.Repeat("-", PROGRESS_BAR_WIDTH-filled), done, total)
+	if done >= total {
+		fmt.Fprint(p.out, "\n")
+	}
+}
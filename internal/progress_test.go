@@ -0,0 +1,20 @@
+package internal
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProgressBarUpdate(t *testing.T) {
+	var out bytes.Buffer
+	bar := NewProgressBar(&out, "Deleting")
+
+	bar.Update(1, 2)
+	assert.Equal(t, "\rDeleting [###############---------------] 1/2", out.String())
+
+	out.Reset()
+	bar.Update(2, 2)
+	assert.Equal(t, "\rDeleting [##############################] 2/2\n", out.String())
+}
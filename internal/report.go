@@ -0,0 +1,220 @@
+package internal
+
+import (
+	htmlTemplate "html/template"
+	"io"
+	"sort"
+	"strings"
+	textTemplate "text/template"
+	"time"
+
+	"github.com/actions/gh-actions-cache/types"
+)
+
+const DEFAULT_QUOTA_IN_GB = 10
+
+type CacheGroupSummary struct {
+	Name        string
+	Count       int
+	SizeInBytes int64
+}
+
+type CacheReport struct {
+	Repo         string
+	GeneratedAt  time.Time
+	UsageInBytes int64
+	QuotaInBytes int64
+	TotalCount   int
+	TopBySize    []types.Cache
+	Stalest      []types.Cache
+	ByRef        []CacheGroupSummary
+	ByPrefix     []CacheGroupSummary
+}
+
+// BuildCacheReport aggregates a full cache listing into the sections rendered by the Markdown and HTML reports.
+// top caps the number of entries shown in the largest and stalest sections.
+func BuildCacheReport(repo string, usageInBytes int64, quotaInBytes int64, caches []types.Cache, top int, now time.Time) CacheReport {
+	report := CacheReport{
+		Repo:         repo,
+		GeneratedAt:  now,
+		UsageInBytes: usageInBytes,
+		QuotaInBytes: quotaInBytes,
+		TotalCount:   len(caches),
+	}
+
+	bySize := append([]types.Cache{}, caches...)
+	sort.SliceStable(bySize, func(i, j int) bool { return bySize[i].SizeInBytes > bySize[j].SizeInBytes })
+	report.TopBySize = firstN(bySize, top)
+
+	byLastAccess := append([]types.Cache{}, caches...)
+	sort.SliceStable(byLastAccess, func(i, j int) bool {
+		return byLastAccess[i].LastAccessedAt.Before(byLastAccess[j].LastAccessedAt)
+	})
+	report.Stalest = firstN(byLastAccess, top)
+
+	report.ByRef = groupCaches(caches, func(c types.Cache) string { return c.Ref.Raw })
+	report.ByPrefix = groupCaches(caches, func(c types.Cache) string { return CacheKeyPrefix(c.Key) })
+	return report
+}
+
+// CacheKeyPrefix drops the last dash separated segment of a key, which is usually the hash of the cached files.
+func CacheKeyPrefix(key string) string {
+	i := strings.LastIndex(key, "-")
+	if i <= 0 {
+		return key
+	}
+	return key[:i+1]
+}
+
+func (r CacheReport) UsagePercent() float64 {
+	if r.QuotaInBytes <= 0 {
+		return 0
+	}
+	return float64(r.UsageInBytes) / float64(r.QuotaInBytes) * 100
+}
+
+func (r CacheReport) DaysSinceAccess(cache types.Cache) int {
+	if cache.LastAccessedAt.IsZero() {
+		return 0
+	}
+	return int(r.GeneratedAt.Sub(cache.LastAccessedAt).Hours() / 24)
+}
+
+func RenderMarkdownReport(w io.Writer, report CacheReport) error {
+	tmpl, err := textTemplate.New("markdown").Funcs(reportFuncs()).Parse(markdownReportTemplate)
+	if err != nil {
+		return err
+	}
+	return tmpl.Execute(w, report)
+}
+
+func RenderHTMLReport(w io.Writer, report CacheReport) error {
+	tmpl, err := htmlTemplate.New("html").Funcs(reportFuncs()).Parse(htmlReportTemplate)
+	if err != nil {
+		return err
+	}
+	return tmpl.Execute(w, report)
+}
+
+func reportFuncs() map[string]interface{} {
+	return map[string]interface{}{
+		"size":      FormatCacheSize,
+		"md":        escapeMarkdownCell,
+		"date":      func(t time.Time) string { return t.UTC().Format(time.RFC3339) },
+		"timestamp": types.FormatApiTime,
+	}
+}
+
+func escapeMarkdownCell(s string) string {
+	return strings.ReplaceAll(s, "|", "\\|")
+}
+
+func groupCaches(caches []types.Cache, groupBy func(types.Cache) string) []CacheGroupSummary {
+	groups := map[string]*CacheGroupSummary{}
+	for _, cache := range caches {
+		name := groupBy(cache)
+		group, ok := groups[name]
+		if !ok {
+			group = &CacheGroupSummary{Name: name}
+			groups[name] = group
+		}
+		group.Count++
+		group.SizeInBytes += cache.SizeInBytes
+	}
+
+	summaries := make([]CacheGroupSummary, 0, len(groups))
+	for _, group := range groups {
+		summaries = append(summaries, *group)
+	}
+	sort.Slice(summaries, func(i, j int) bool {
+		if summaries[i].SizeInBytes == summaries[j].SizeInBytes {
+			return summaries[i].Name < summaries[j].Name
+		}
+		return summaries[i].SizeInBytes > summaries[j].SizeInBytes
+	})
+	return summaries
+}
+
+func firstN(caches []types.Cache, n int) []types.Cache {
+	if n > 0 && len(caches) > n {
+		return caches[:n]
+	}
+	return caches
+}
+
+const markdownReportTemplate = `## Actions cache report for {{.Repo}}
+
+Generated at {{date .GeneratedAt}}
+
+| Usage | Quota | Used | Entries |
+| --- | --- | --- | --- |
+| {{size .UsageInBytes}} | {{size .QuotaInBytes}} | {{printf "%.1f" .UsagePercent}}% | {{.TotalCount}} |
+
+### Largest entries
+
+| Key | Ref | Size |
+| --- | --- | --- |
+{{range .TopBySize}}| {{md .Key}} | {{md .Ref.Raw}} | {{size .SizeInBytes}} |
+{{end}}
+### Stalest entries
+
+| Key | Ref | Last accessed | Days since access |
+| --- | --- | --- | --- |
+{{range .Stalest}}| {{md .Key}} | {{md .Ref.Raw}} | {{timestamp .LastAccessedAt}} | {{$.DaysSinceAccess .}} |
+{{end}}
+### Usage by ref
+
+| Ref | Entries | Size |
+| --- | --- | --- |
+{{range .ByRef}}| {{md .Name}} | {{.Count}} | {{size .SizeInBytes}} |
+{{end}}
+### Usage by key prefix
+
+| Key prefix | Entries | Size |
+| --- | --- | --- |
+{{range .ByPrefix}}| {{md .Name}} | {{.Count}} | {{size .SizeInBytes}} |
+{{end}}`
+
+const htmlReportTemplate = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Actions cache report for {{.Repo}}</title>
+<style>
+body { font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", Helvetica, Arial, sans-serif; margin: 2em; color: #24292f; }
+table { border-collapse: collapse; margin-bottom: 2em; }
+th, td { border: 1px solid #d0d7de; padding: 6px 13px; text-align: left; }
+th { background: #f6f8fa; }
+td.key { font-family: monospace; word-break: break-all; }
+.meter { width: 320px; height: 12px; background: #eaeef2; border-radius: 6px; overflow: hidden; }
+.meter div { height: 100%; background: #2da44e; }
+</style>
+</head>
+<body>
+<h1>Actions cache report for {{.Repo}}</h1>
+<p>Generated at {{date .GeneratedAt}}</p>
+<p>{{size .UsageInBytes}} of {{size .QuotaInBytes}} used ({{printf "%.1f" .UsagePercent}}%) across {{.TotalCount}} entries</p>
+<div class="meter"><div style="width: {{printf "%.1f" .UsagePercent}}%"></div></div>
+<h2>Largest entries</h2>
+<table>
+<tr><th>Key</th><th>Ref</th><th>Size</th></tr>
+{{range .TopBySize}}<tr><td class="key">{{.Key}}</td><td>{{.Ref.Raw}}</td><td>{{size .SizeInBytes}}</td></tr>
+{{end}}</table>
+<h2>Stalest entries</h2>
+<table>
+<tr><th>Key</th><th>Ref</th><th>Last accessed</th><th>Days since access</th></tr>
+{{range .Stalest}}<tr><td class="key">{{.Key}}</td><td>{{.Ref.Raw}}</td><td>{{timestamp .LastAccessedAt}}</td><td>{{$.DaysSinceAccess .}}</td></tr>
+{{end}}</table>
+<h2>Usage by ref</h2>
+<table>
+<tr><th>Ref</th><th>Entries</th><th>Size</th></tr>
+{{range .ByRef}}<tr><td>{{.Name}}</td><td>{{.Count}}</td><td>{{size .SizeInBytes}}</td></tr>
+{{end}}</table>
+<h2>Usage by key prefix</h2>
+<table>
+<tr><th>Key prefix</th><th>Entries</th><th>Size</th></tr>
+{{range .ByPrefix}}<tr><td class="key">{{.Name}}</td><td>{{.Count}}</td><td>{{size .SizeInBytes}}</td></tr>
+{{end}}</table>
+</body>
+</html>
+`
@@ -0,0 +1,72 @@
+package internal
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/actions/gh-actions-cache/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func reportTestCaches() []types.Cache {
+	return []types.Cache{
+		{Id: 1, Ref: types.ParseRef("refs/heads/main"), Key: "Linux-node-aaa", LastAccessedAt: apiTime("2022-06-20T00:00:00Z"), SizeInBytes: 300},
+		{Id: 2, Ref: types.ParseRef("refs/pull/2/merge"), Key: "Linux-node-bbb", LastAccessedAt: apiTime("2022-06-01T00:00:00Z"), SizeInBytes: 100},
+		{Id: 3, Ref: types.ParseRef("refs/heads/main"), Key: "Windows-go-ccc", LastAccessedAt: apiTime("2022-06-10T00:00:00Z"), SizeInBytes: 200},
+	}
+}
+
+func TestBuildCacheReport(t *testing.T) {
+	now := time.Date(2022, 6, 21, 0, 0, 0, 0, time.UTC)
+	report := BuildCacheReport("testOrg/testRepo", 600, 1200, reportTestCaches(), 2, now)
+
+	assert.Equal(t, 3, report.TotalCount)
+	assert.Equal(t, float64(50), report.UsagePercent())
+	if assert.Len(t, report.TopBySize, 2) {
+		assert.Equal(t, int64(1), report.TopBySize[0].Id)
+		assert.Equal(t, int64(3), report.TopBySize[1].Id)
+	}
+	if assert.Len(t, report.Stalest, 2) {
+		assert.Equal(t, int64(2), report.Stalest[0].Id)
+		assert.Equal(t, 20, report.DaysSinceAccess(report.Stalest[0]))
+	}
+	assert.Equal(t, []CacheGroupSummary{
+		{Name: "refs/heads/main", Count: 2, SizeInBytes: 500},
+		{Name: "refs/pull/2/merge", Count: 1, SizeInBytes: 100},
+	}, report.ByRef)
+	assert.Equal(t, []CacheGroupSummary{
+		{Name: "Linux-node-", Count: 2, SizeInBytes: 400},
+		{Name: "Windows-go-", Count: 1, SizeInBytes: 200},
+	}, report.ByPrefix)
+}
+
+func TestCacheKeyPrefix(t *testing.T) {
+	assert.Equal(t, "Linux-node-", CacheKeyPrefix("Linux-node-f5dbf39c9d11eba80242ac13"))
+	assert.Equal(t, "nodashes", CacheKeyPrefix("nodashes"))
+}
+
+func TestRenderMarkdownReport(t *testing.T) {
+	now := time.Date(2022, 6, 21, 0, 0, 0, 0, time.UTC)
+	report := BuildCacheReport("testOrg/testRepo", 600, 1200, reportTestCaches(), 10, now)
+	var out bytes.Buffer
+	err := RenderMarkdownReport(&out, report)
+
+	assert.NoError(t, err)
+	assert.Contains(t, out.String(), "## Actions cache report for testOrg/testRepo")
+	assert.Contains(t, out.String(), "| 600.00 B | 1.17 KB | 50.0% | 3 |")
+	assert.Contains(t, out.String(), "| Linux-node-bbb | refs/pull/2/merge | 2022-06-01T00:00:00Z | 20 |")
+	assert.Contains(t, out.String(), "| Linux-node- | 2 | 400.00 B |")
+}
+
+func TestRenderHTMLReportEscapesKeys(t *testing.T) {
+	caches := []types.Cache{{Id: 1, Ref: types.ParseRef("refs/heads/main"), Key: "<script>", LastAccessedAt: apiTime("2022-06-20T00:00:00Z"), SizeInBytes: 300}}
+	report := BuildCacheReport("testOrg/testRepo", 300, 1200, caches, 10, time.Now())
+	var out bytes.Buffer
+	err := RenderHTMLReport(&out, report)
+
+	assert.NoError(t, err)
+	assert.Contains(t, out.String(), "<title>Actions cache report for testOrg/testRepo</title>")
+	assert.Contains(t, out.String(), "&lt;script&gt;")
+	assert.NotContains(t, out.String(), "<td class=\"key\"><script>")
+}
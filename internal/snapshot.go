@@ -0,0 +1,98 @@
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/actions/gh-actions-cache/types"
+)
+
+type AccessedCache struct {
+	Previous types.Cache
+	Current  types.Cache
+}
+
+type SnapshotDiff struct {
+	Added            []types.Cache
+	Removed          []types.Cache
+	Accessed         []AccessedCache
+	SizeDeltaInBytes int64
+}
+
+func SaveSnapshot(path string, snapshot types.CacheSnapshot) error {
+	data, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+func LoadSnapshot(path string) (types.CacheSnapshot, error) {
+	var snapshot types.CacheSnapshot
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return snapshot, err
+	}
+	err = json.Unmarshal(data, &snapshot)
+	return snapshot, err
+}
+
+// DiffSnapshots matches entries by id. Entries only present in previous were deleted or evicted in between,
+// entries present in both whose last_accessed_at changed have been restored from since the previous snapshot.
+func DiffSnapshots(previous []types.Cache, current []types.Cache) SnapshotDiff {
+	diff := SnapshotDiff{}
+	previousById := map[int64]types.Cache{}
+	for _, cache := range previous {
+		previousById[cache.Id] = cache
+		diff.SizeDeltaInBytes -= cache.SizeInBytes
+	}
+
+	currentIds := map[int64]bool{}
+	for _, cache := range current {
+		currentIds[cache.Id] = true
+		diff.SizeDeltaInBytes += cache.SizeInBytes
+		old, ok := previousById[cache.Id]
+		if !ok {
+			diff.Added = append(diff.Added, cache)
+		} else if !old.LastAccessedAt.Equal(cache.LastAccessedAt) {
+			diff.Accessed = append(diff.Accessed, AccessedCache{Previous: old, Current: cache})
+		}
+	}
+
+	for _, cache := range previous {
+		if !currentIds[cache.Id] {
+			diff.Removed = append(diff.Removed, cache)
+		}
+	}
+
+	sort.SliceStable(diff.Added, func(i, j int) bool { return diff.Added[i].Key < diff.Added[j].Key })
+	sort.SliceStable(diff.Removed, func(i, j int) bool { return diff.Removed[i].Key < diff.Removed[j].Key })
+	sort.SliceStable(diff.Accessed, func(i, j int) bool { return diff.Accessed[i].Current.Key < diff.Accessed[j].Current.Key })
+	return diff
+}
+
+func PrintSnapshotDiff(w io.Writer, diff SnapshotDiff) {
+	fmt.Fprintf(w, "Added: %s\n", PrintSingularOrPlural(len(diff.Added), "cache entry", "cache entries"))
+	for _, cache := range diff.Added {
+		fmt.Fprintf(w, "  + %s\t%s\t%s\n", cache.Key, cache.Ref.Raw, FormatCacheSize(cache.SizeInBytes))
+	}
+
+	fmt.Fprintf(w, "\nDeleted or evicted: %s\n", PrintSingularOrPlural(len(diff.Removed), "cache entry", "cache entries"))
+	for _, cache := range diff.Removed {
+		fmt.Fprintf(w, "  - %s\t%s\t%s\n", cache.Key, cache.Ref.Raw, FormatCacheSize(cache.SizeInBytes))
+	}
+
+	fmt.Fprintf(w, "\nAccessed: %s\n", PrintSingularOrPlural(len(diff.Accessed), "cache entry", "cache entries"))
+	for _, accessed := range diff.Accessed {
+		fmt.Fprintf(w, "  ~ %s\t%s\t%s -> %s\n", accessed.Current.Key, accessed.Current.Ref.Raw, types.FormatApiTime(accessed.Previous.LastAccessedAt), types.FormatApiTime(accessed.Current.LastAccessedAt))
+	}
+
+	sign, delta := "+", diff.SizeDeltaInBytes
+	if delta < 0 {
+		sign, delta = "-", -delta
+	}
+	fmt.Fprintf(w, "\nNet size change: %s%s\n", sign, FormatCacheSize(delta))
+}
@@ -0,0 +1,60 @@
+package internal
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/actions/gh-actions-cache/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffSnapshots(t *testing.T) {
+	previous := []types.Cache{
+		{Id: 1, Key: "Linux-node-a", LastAccessedAt: apiTime("2022-06-20T00:00:00Z"), SizeInBytes: 100},
+		{Id: 2, Key: "Linux-node-b", LastAccessedAt: apiTime("2022-06-20T00:00:00Z"), SizeInBytes: 200},
+		{Id: 3, Key: "Linux-node-c", LastAccessedAt: apiTime("2022-06-20T00:00:00Z"), SizeInBytes: 300},
+	}
+	current := []types.Cache{
+		{Id: 2, Key: "Linux-node-b", LastAccessedAt: apiTime("2022-06-21T00:00:00Z"), SizeInBytes: 200},
+		{Id: 3, Key: "Linux-node-c", LastAccessedAt: apiTime("2022-06-20T00:00:00Z"), SizeInBytes: 300},
+		{Id: 4, Key: "Linux-node-d", LastAccessedAt: apiTime("2022-06-21T00:00:00Z"), SizeInBytes: 50},
+	}
+
+	diff := DiffSnapshots(previous, current)
+
+	if assert.Len(t, diff.Added, 1) {
+		assert.Equal(t, int64(4), diff.Added[0].Id)
+	}
+	if assert.Len(t, diff.Removed, 1) {
+		assert.Equal(t, int64(1), diff.Removed[0].Id)
+	}
+	if assert.Len(t, diff.Accessed, 1) {
+		assert.Equal(t, apiTime("2022-06-20T00:00:00Z"), diff.Accessed[0].Previous.LastAccessedAt)
+		assert.Equal(t, apiTime("2022-06-21T00:00:00Z"), diff.Accessed[0].Current.LastAccessedAt)
+	}
+	assert.Equal(t, int64(-50), diff.SizeDeltaInBytes)
+
+	var out bytes.Buffer
+	PrintSnapshotDiff(&out, diff)
+	assert.Contains(t, out.String(), "Deleted or evicted: 1 cache entry\n  - Linux-node-a")
+	assert.Contains(t, out.String(), "Net size change: -50.00 B")
+}
+
+func TestSaveAndLoadSnapshot(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "snapshot.json")
+	snapshot := types.CacheSnapshot{
+		Repo:       "github.com/testOrg/testRepo",
+		TakenAt:    time.Date(2022, 6, 21, 0, 0, 0, 0, time.UTC),
+		TotalCount: 1,
+		Caches:     []types.Cache{{Id: 1, Key: "Linux-node-a", SizeInBytes: 100}},
+	}
+
+	require.NoError(t, SaveSnapshot(path, snapshot))
+	loaded, err := LoadSnapshot(path)
+
+	assert.NoError(t, err)
+	assert.Equal(t, snapshot, loaded)
+}
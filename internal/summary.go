@@ -0,0 +1,70 @@
+package internal
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/actions/gh-actions-cache/types"
+)
+
+const STEP_SUMMARY_ENV = "GITHUB_STEP_SUMMARY"
+
+// AppendStepSummary appends the output of write to the job summary file of the current GitHub Actions step.
+// It is a no-op outside of GitHub Actions, i.e. when GITHUB_STEP_SUMMARY is not set.
+func AppendStepSummary(write func(w io.Writer) error) error {
+	path := os.Getenv(STEP_SUMMARY_ENV)
+	if path == "" {
+		return nil
+	}
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return write(file)
+}
+
+func WriteListSummary(w io.Writer, repo string, caches []types.Cache) error {
+	_, err := fmt.Fprintf(w, "### Actions caches in %s\n\n%s in the listing, %s in total\n\n", repo, PrintSingularOrPlural(len(caches), "cache entry", "cache entries"), FormatCacheSize(totalSize(caches)))
+	if err != nil {
+		return err
+	}
+	return writeMarkdownCacheTable(w, caches)
+}
+
+func WriteDeleteSummary(w io.Writer, repo string, caches []types.Cache) error {
+	_, err := fmt.Fprintf(w, "### Deleted Actions caches in %s\n\n%s deleted, %s reclaimed\n\n", repo, PrintSingularOrPlural(len(caches), "cache entry", "cache entries"), FormatCacheSize(totalSize(caches)))
+	if err != nil {
+		return err
+	}
+	return writeMarkdownCacheTable(w, caches)
+}
+
+func writeMarkdownCacheTable(w io.Writer, caches []types.Cache) error {
+	if len(caches) == 0 {
+		return nil
+	}
+
+	if _, err := fmt.Fprint(w, "| Key | Ref | Size | Size in bytes |\n| --- | --- | --- | --- |\n"); err != nil {
+		return err
+	}
+	for _, cache := range caches {
+		_, err := fmt.Fprintf(w, "| %s | %s | %s | %d |\n", escapeMarkdownCell(cache.Key), escapeMarkdownCell(cache.Ref.Raw), FormatCacheSize(cache.SizeInBytes), cache.SizeInBytes)
+		if err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprint(w, "\n")
+	return err
+}
+
+func totalSize(caches []types.Cache) int64 {
+	var total int64
+	for _, cache := range caches {
+		total += cache.SizeInBytes
+	}
+	return total
+}
@@ -0,0 +1,57 @@
+package internal
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/actions/gh-actions-cache/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAppendStepSummary_NoopOutsideActions(t *testing.T) {
+	t.Setenv(STEP_SUMMARY_ENV, "")
+	called := false
+	err := AppendStepSummary(func(w io.Writer) error {
+		called = true
+		return nil
+	})
+
+	assert.NoError(t, err)
+	assert.False(t, called)
+}
+
+func TestAppendStepSummary_AppendsToFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "summary.md")
+	require.NoError(t, os.WriteFile(path, []byte("existing\n"), 0644))
+	t.Setenv(STEP_SUMMARY_ENV, path)
+
+	err := AppendStepSummary(func(w io.Writer) error {
+		_, err := io.WriteString(w, "appended\n")
+		return err
+	})
+
+	assert.NoError(t, err)
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "existing\nappended\n", string(content))
+}
+
+func TestWriteDeleteSummary(t *testing.T) {
+	caches := []types.Cache{
+		{Id: 1, Ref: types.ParseRef("refs/heads/main"), Key: "Linux-node-a", SizeInBytes: 1024},
+		{Id: 2, Ref: types.ParseRef("refs/pull/2/merge"), Key: "Linux-node-b", SizeInBytes: 1024},
+	}
+	var out bytes.Buffer
+	err := WriteDeleteSummary(&out, "testOrg/testRepo", caches)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "### Deleted Actions caches in testOrg/testRepo\n\n"+
+		"2 cache entries deleted, 2.00 KB reclaimed\n\n"+
+		"| Key | Ref | Size | Size in bytes |\n| --- | --- | --- | --- |\n"+
+		"| Linux-node-a | refs/heads/main | 1.00 KB | 1024 |\n"+
+		"| Linux-node-b | refs/pull/2/merge | 1.00 KB | 1024 |\n\n", out.String())
+}
@@ -0,0 +1,85 @@
+package internal
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/actions/gh-actions-cache/types"
+)
+
+// CacheTarget is a cache to delete, identified either by key or by id. Cache holds the full entry when the
+// target was read from a JSON listing.
+type CacheTarget struct {
+	Key   string
+	Id    int64
+	Cache *types.Cache
+}
+
+func (t CacheTarget) IsId() bool {
+	return t.Key == ""
+}
+
+func (t CacheTarget) String() string {
+	if t.IsId() {
+		return fmt.Sprintf("id '%d'", t.Id)
+	}
+	return fmt.Sprintf("key '%s'", t.Key)
+}
+
+// ParseCacheTargets reads cache keys or ids, one per line, or the JSON output of list (an array of caches or an
+// object with an actions_caches array like the API response and snapshots). Lines made of digits only are ids,
+// blank lines and lines starting with # are ignored.
+func ParseCacheTargets(r io.Reader) ([]CacheTarget, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) > 0 && (trimmed[0] == '[' || trimmed[0] == '{') {
+		return parseJSONCacheTargets(trimmed)
+	}
+
+	var targets []CacheTarget
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if id, err := strconv.ParseInt(line, 10, 64); err == nil && id > 0 {
+			targets = append(targets, CacheTarget{Id: id})
+		} else {
+			targets = append(targets, CacheTarget{Key: line})
+		}
+	}
+	return targets, scanner.Err()
+}
+
+func parseJSONCacheTargets(data []byte) ([]CacheTarget, error) {
+	var caches []types.Cache
+	if data[0] == '[' {
+		if err := json.Unmarshal(data, &caches); err != nil {
+			return nil, err
+		}
+	} else {
+		var listing struct {
+			ActionsCaches []types.Cache `json:"actions_caches"`
+		}
+		if err := json.Unmarshal(data, &listing); err != nil {
+			return nil, err
+		}
+		caches = listing.ActionsCaches
+	}
+
+	targets := make([]CacheTarget, 0, len(caches))
+	for i := range caches {
+		targets = append(targets, CacheTarget{Id: caches[i].Id, Cache: &caches[i]})
+	}
+	return targets, nil
+}
@@ -0,0 +1,43 @@
+package internal
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCacheTargets_Lines(t *testing.T) {
+	targets, err := ParseCacheTargets(strings.NewReader("# comment\nLinux-node-a\n\n  1293  \n2022-06-29T13:33:49\n"))
+
+	assert.NoError(t, err)
+	assert.Equal(t, []CacheTarget{{Key: "Linux-node-a"}, {Id: 1293}, {Key: "2022-06-29T13:33:49"}}, targets)
+	assert.Equal(t, "key 'Linux-node-a'", targets[0].String())
+	assert.Equal(t, "id '1293'", targets[1].String())
+}
+
+func TestParseCacheTargets_JSONArray(t *testing.T) {
+	targets, err := ParseCacheTargets(strings.NewReader(`[{"id": 1, "key": "a"}, {"id": 2, "key": "b"}]`))
+
+	assert.NoError(t, err)
+	if assert.Len(t, targets, 2) {
+		assert.Equal(t, int64(2), targets[1].Id)
+		assert.Equal(t, "b", targets[1].Cache.Key)
+		assert.True(t, targets[1].IsId())
+	}
+}
+
+func TestParseCacheTargets_JSONListing(t *testing.T) {
+	targets, err := ParseCacheTargets(strings.NewReader(`{"total_count": 1, "actions_caches": [{"id": 7, "key": "a"}]}`))
+
+	assert.NoError(t, err)
+	if assert.Len(t, targets, 1) {
+		assert.Equal(t, int64(7), targets[0].Id)
+	}
+}
+
+func TestParseCacheTargets_InvalidJSON(t *testing.T) {
+	_, err := ParseCacheTargets(strings.NewReader(`[{"id": "x"}]`))
+
+	assert.Error(t, err)
+}
@@ -1,8 +1,13 @@
 package internal
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
 	"unicode/utf8"
 
 	"github.com/TwiN/go-color"
@@ -18,6 +23,14 @@ import (
 const MB_IN_BYTES = 1024 * 1024
 const GB_IN_BYTES = 1024 * 1024 * 1024
 
+// DisplayOptions configures the cache tables. The root command sets it from the configuration.
+type DisplayOptions struct {
+	Columns    []string
+	TimeFormat string
+}
+
+var Display = DisplayOptions{Columns: types.DEFAULT_TABLE_COLUMNS, TimeFormat: "relative"}
+
 func GetRepo(r string) (ghRepo.Repository, error) {
 	if r != "" {
 		return ghRepo.Parse(r)
@@ -26,39 +39,39 @@ func GetRepo(r string) (ghRepo.Repository, error) {
 	return gh.CurrentRepository()
 }
 
-func FormatCacheSize(size_in_bytes float64) string {
-	if size_in_bytes < 1024 {
-		return fmt.Sprintf("%.2f B", size_in_bytes)
+func FormatCacheSize(size_in_bytes int64) string {
+	size := float64(size_in_bytes)
+	if size < 1024 {
+		return fmt.Sprintf("%.2f B", size)
 	}
 
-	if size_in_bytes < MB_IN_BYTES {
-		return fmt.Sprintf("%.2f KB", size_in_bytes/1024)
+	if size < MB_IN_BYTES {
+		return fmt.Sprintf("%.2f KB", size/1024)
 	}
 
-	if size_in_bytes < GB_IN_BYTES {
-		return fmt.Sprintf("%.2f MB", size_in_bytes/MB_IN_BYTES)
+	if size < GB_IN_BYTES {
+		return fmt.Sprintf("%.2f MB", size/MB_IN_BYTES)
 	}
 
-	return fmt.Sprintf("%.2f GB", size_in_bytes/GB_IN_BYTES)
+	return fmt.Sprintf("%.2f GB", size/GB_IN_BYTES)
 }
 
-func PrettyPrintCacheList(caches []types.ActionsCache) {
+func PrettyPrintCacheList(caches []types.Cache) {
 	terminal := ghTerm.FromEnv()
 	w, _, _ := terminal.Size()
 	tp := ghTableprinter.New(terminal.Out(), terminal.IsTerminalOutput(), w)
 
 	for _, cache := range caches {
-		tp.AddField(cache.Key)
-		tp.AddField(FormatCacheSize(cache.SizeInBytes))
-		tp.AddField(cache.Ref)
-		tp.AddField(lastAccessedTime(cache.LastAccessedAt))
+		for _, column := range Display.Columns {
+			tp.AddField(cacheField(cache, column))
+		}
 		tp.EndRow()
 	}
 
 	_ = tp.Render()
 }
 
-func PrettyPrintTrimmedCacheList(caches []types.ActionsCache) {
+func PrettyPrintTrimmedCacheList(caches []types.Cache) {
 	length := len(caches)
 	limit := 30
 	if length > limit {
@@ -70,9 +83,42 @@ func PrettyPrintTrimmedCacheList(caches []types.ActionsCache) {
 	fmt.Print("\n")
 }
 
-func lastAccessedTime(lastAccessedAt string) string {
-	lastAccessed, _ := goment.New(lastAccessedAt)
-	return lastAccessed.FromNow()
+// cacheField formats a column of types.TABLE_COLUMNS.
+func cacheField(cache types.Cache, column string) string {
+	switch column {
+	case "size":
+		return FormatCacheSize(cache.SizeInBytes)
+	case "ref":
+		return cache.Ref.Raw
+	case "last-used":
+		return lastAccessedTime(cache.LastAccessedAt)
+	case "created-at":
+		return formatTime(cache.CreatedAt)
+	case "id":
+		return strconv.FormatInt(cache.Id, 10)
+	case "version":
+		return cache.Version
+	}
+	return cache.Key
+}
+
+func lastAccessedTime(lastAccessedAt time.Time) string {
+	if lastAccessedAt.IsZero() {
+		return "never"
+	}
+	return formatTime(lastAccessedAt)
+}
+
+// formatTime shows t relative to now, or as a local date and time with the absolute time format.
+func formatTime(t time.Time) string {
+	if Display.TimeFormat == "absolute" {
+		return t.Local().Format("2006-01-02 15:04:05")
+	}
+	relative, err := goment.New(t)
+	if err != nil {
+		return t.String()
+	}
+	return relative.FromNow()
 }
 
 func RedTick() string {
@@ -82,6 +128,10 @@ func RedTick() string {
 	return redTick
 }
 
+func RedCross() string {
+	return color.Colorize(color.Red, "\u2717")
+}
+
 func PrintSingularOrPlural(count int, singularStr string, pluralStr string) string {
 	if count == 1 {
 		return fmt.Sprintf("%d %s", count, singularStr)
@@ -91,11 +141,57 @@ func PrintSingularOrPlural(count int, singularStr string, pluralStr string) stri
 
 func HttpErrorHandler(err error, errMsg404 string) types.HandledError {
 	var httpError api.HTTPError
-	if errors.As(err, &httpError) && httpError.StatusCode == 404 {
-		return types.HandledError{Message: errMsg404, InnerError: err}
+	if errors.Is(err, types.ErrInterrupted) || errors.Is(err, context.Canceled) {
+		return types.HandledError{Message: "The operation was interrupted.", InnerError: err, Kind: types.ErrorKindCancelled}
+	} else if errors.Is(err, context.DeadlineExceeded) {
+		return types.HandledError{Message: "The operation timed out.", InnerError: err, Kind: types.ErrorKindTimeout}
+	} else if errors.As(err, &httpError) && httpError.StatusCode == 404 {
+		return types.HandledError{Message: errMsg404, InnerError: err, Kind: types.ErrorKindNotFound}
+	} else if errors.As(err, &httpError) && isRateLimited(httpError) {
+		return types.HandledError{Message: httpError.Message, InnerError: err, Kind: types.ErrorKindRateLimited}
+	} else if errors.As(err, &httpError) && (httpError.StatusCode == 401 || httpError.StatusCode == 403) {
+		return types.HandledError{Message: httpError.Message + missingScopesHint(httpError), InnerError: err, Kind: types.ErrorKindForbidden}
+	} else if errors.As(err, &httpError) && (httpError.StatusCode == 400 || httpError.StatusCode == 422) {
+		return types.HandledError{Message: httpError.Message, InnerError: err, Kind: types.ErrorKindValidation}
 	} else if errors.As(err, &httpError) && httpError.StatusCode >= 400 && httpError.StatusCode < 500 {
 		return types.HandledError{Message: httpError.Message, InnerError: err}
 	} else {
 		return types.HandledError{Message: "We could not process your request due to internal error.", InnerError: err}
 	}
 }
+
+// isRateLimited tells a rate limit from other 403 responses, the same way the retries of the service do.
+func isRateLimited(httpError api.HTTPError) bool {
+	if httpError.StatusCode == http.StatusTooManyRequests {
+		return true
+	}
+	return httpError.StatusCode == http.StatusForbidden && (httpError.Headers.Get("Retry-After") != "" || httpError.Headers.Get("X-RateLimit-Remaining") == "0")
+}
+
+// missingScopesHint names the OAuth scopes accepted by the endpoint when the token has none of them.
+func missingScopesHint(httpError api.HTTPError) string {
+	accepted := splitScopes(httpError.Headers.Get("X-Accepted-OAuth-Scopes"))
+	if len(accepted) == 0 {
+		return ""
+	}
+	granted := map[string]bool{}
+	for _, scope := range splitScopes(httpError.Headers.Get("X-OAuth-Scopes")) {
+		granted[scope] = true
+	}
+	for _, scope := range accepted {
+		if granted[scope] {
+			return ""
+		}
+	}
+	return fmt.Sprintf(" The token is missing one of the scopes: %s.", strings.Join(accepted, ", "))
+}
+
+func splitScopes(header string) []string {
+	var scopes []string
+	for _, scope := range strings.Split(header, ",") {
+		if scope = strings.TrimSpace(scope); scope != "" {
+			scopes = append(scopes, scope)
+		}
+	}
+	return scopes
+}
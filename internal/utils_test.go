@@ -1,9 +1,13 @@
 package internal
 
 import (
+	"context"
 	"fmt"
+	"net/http"
 	"testing"
 
+	"github.com/actions/gh-actions-cache/types"
+	"github.com/cli/go-gh/pkg/api"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -40,15 +44,43 @@ func TestGetRepo_CorrectRepoStringWithCustomHost(t *testing.T) {
 }
 
 func TestFormatCacheSize_MB(t *testing.T) {
-	cacheSizeInBytes := 1024 * 1024 * 1.5
+	cacheSizeInBytes := int64(1024 * 1024 * 1.5)
 	cacheSizeDetailString := FormatCacheSize(cacheSizeInBytes)
 
 	assert.Equal(t, "1.50 MB", cacheSizeDetailString)
 }
 
 func TestFormatCacheSize_GB(t *testing.T) {
-	cacheSizeInBytes := 1024 * 1024 * 1024 * 1.5
+	cacheSizeInBytes := int64(1024 * 1024 * 1024 * 1.5)
 	cacheSizeDetailString := FormatCacheSize(cacheSizeInBytes)
 
 	assert.Equal(t, "1.50 GB", cacheSizeDetailString)
 }
+
+func TestHttpErrorHandler_ContextErrors(t *testing.T) {
+	assert.Equal(t, "The operation was interrupted.", HttpErrorHandler(fmt.Errorf("listing: %w", types.ErrInterrupted), "").Message)
+	assert.Equal(t, "The operation was interrupted.", HttpErrorHandler(context.Canceled, "").Message)
+	assert.Equal(t, "The operation timed out.", HttpErrorHandler(fmt.Errorf("Get: %w", context.DeadlineExceeded), "").Message)
+}
+
+func TestHttpErrorHandler_Kinds(t *testing.T) {
+	rateLimited := api.HTTPError{StatusCode: 403, Message: "API rate limit exceeded", Headers: http.Header{"X-Ratelimit-Remaining": []string{"0"}}}
+	assert.Equal(t, types.ErrorKindRateLimited, HttpErrorHandler(rateLimited, "").Kind)
+	assert.Equal(t, types.ErrorKindRateLimited, HttpErrorHandler(api.HTTPError{StatusCode: 429}, "").Kind)
+	assert.Equal(t, types.ErrorKindNotFound, HttpErrorHandler(api.HTTPError{StatusCode: 404}, "Not here").Kind)
+	assert.Equal(t, types.ErrorKindValidation, HttpErrorHandler(api.HTTPError{StatusCode: 422}, "").Kind)
+	assert.Equal(t, types.ErrorKindInternal, HttpErrorHandler(api.HTTPError{StatusCode: 502}, "").Kind)
+	assert.Equal(t, types.ErrorKindCancelled, HttpErrorHandler(context.Canceled, "").Kind)
+}
+
+func TestHttpErrorHandler_MissingScope(t *testing.T) {
+	forbidden := api.HTTPError{StatusCode: 403, Message: "Resource not accessible by integration.", Headers: http.Header{
+		"X-Accepted-Oauth-Scopes": []string{"repo"},
+		"X-Oauth-Scopes":          []string{"gist, read:org"},
+	}}
+
+	handledError := HttpErrorHandler(forbidden, "")
+
+	assert.Equal(t, types.ErrorKindForbidden, handledError.Kind)
+	assert.Equal(t, "Resource not accessible by integration. The token is missing one of the scopes: repo.", handledError.Message)
+}
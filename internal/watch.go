@@ -0,0 +1,68 @@
+package internal
+
+import (
+	"github.com/TwiN/go-color"
+	"github.com/actions/gh-actions-cache/types"
+	ghTableprinter "github.com/cli/go-gh/pkg/tableprinter"
+	ghTerm "github.com/cli/go-gh/pkg/term"
+)
+
+const CLEAR_SCREEN = "\033[H\033[2J"
+
+const (
+	WATCH_STATUS_NEW      = "new"
+	WATCH_STATUS_ACCESSED = "accessed"
+	WATCH_STATUS_REMOVED  = "removed"
+)
+
+// WatchRowStatus reports how a cache changed since the previous poll, or an empty string if it did not.
+func WatchRowStatus(cache types.Cache, diff SnapshotDiff) string {
+	for _, added := range diff.Added {
+		if added.Id == cache.Id {
+			return WATCH_STATUS_NEW
+		}
+	}
+	for _, accessed := range diff.Accessed {
+		if accessed.Current.Id == cache.Id {
+			return WATCH_STATUS_ACCESSED
+		}
+	}
+	return ""
+}
+
+// PrettyPrintWatchedCacheList prints the caches like PrettyPrintCacheList with an extra status column. Entries
+// removed since the previous poll are listed at the end.
+func PrettyPrintWatchedCacheList(caches []types.Cache, diff SnapshotDiff) {
+	terminal := ghTerm.FromEnv()
+	w, _, _ := terminal.Size()
+	tp := ghTableprinter.New(terminal.Out(), terminal.IsTerminalOutput(), w)
+
+	for _, cache := range caches {
+		addWatchedCacheRow(tp, cache, WatchRowStatus(cache, diff))
+	}
+	for _, cache := range diff.Removed {
+		addWatchedCacheRow(tp, cache, WATCH_STATUS_REMOVED)
+	}
+
+	_ = tp.Render()
+}
+
+func addWatchedCacheRow(tp ghTableprinter.TablePrinter, cache types.Cache, status string) {
+	colorize := func(s string) string {
+		switch status {
+		case WATCH_STATUS_NEW:
+			return color.Colorize(color.Green, s)
+		case WATCH_STATUS_ACCESSED:
+			return color.Colorize(color.Yellow, s)
+		case WATCH_STATUS_REMOVED:
+			return color.Colorize(color.Red, s)
+		}
+		return s
+	}
+
+	for _, column := range Display.Columns {
+		tp.AddField(cacheField(cache, column), ghTableprinter.WithColor(colorize))
+	}
+	tp.AddField(status, ghTableprinter.WithColor(colorize))
+	tp.EndRow()
+}
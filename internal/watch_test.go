@@ -0,0 +1,26 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/actions/gh-actions-cache/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWatchRowStatus(t *testing.T) {
+	previous := []types.Cache{
+		{Id: 1, Key: "Linux-node-a", LastAccessedAt: apiTime("2022-06-20T00:00:00Z")},
+		{Id: 2, Key: "Linux-node-b", LastAccessedAt: apiTime("2022-06-20T00:00:00Z")},
+	}
+	current := []types.Cache{
+		{Id: 1, Key: "Linux-node-a", LastAccessedAt: apiTime("2022-06-21T00:00:00Z")},
+		{Id: 2, Key: "Linux-node-b", LastAccessedAt: apiTime("2022-06-20T00:00:00Z")},
+		{Id: 3, Key: "Linux-node-c", LastAccessedAt: apiTime("2022-06-21T00:00:00Z")},
+	}
+	diff := DiffSnapshots(previous, current)
+
+	assert.Equal(t, WATCH_STATUS_ACCESSED, WatchRowStatus(current[0], diff))
+	assert.Equal(t, "", WatchRowStatus(current[1], diff))
+	assert.Equal(t, WATCH_STATUS_NEW, WatchRowStatus(current[2], diff))
+	assert.Equal(t, "", WatchRowStatus(current[2], SnapshotDiff{}))
+}
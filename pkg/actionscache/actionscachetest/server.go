@@ -0,0 +1,146 @@
+package actionscachetest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/actions/gh-actions-cache/pkg/actionscache"
+	"github.com/actions/gh-actions-cache/types"
+	"github.com/cli/go-gh/pkg/api"
+	ghRepo "github.com/cli/go-gh/pkg/repository"
+)
+
+var cachesPathRE = regexp.MustCompile(`^/api/v3/repos/([^/]+)/([^/]+)/actions/(caches|cache/usage)(?:/(\d+))?$`)
+
+// Server is a fake GitHub cache API for one repository, served over TLS like a GitHub Enterprise Server host.
+type Server struct {
+	*Store
+	*httptest.Server
+	Owner string
+	Name  string
+	repo  ghRepo.Repository
+	// BeforePage is called before serving every page of a listing, e.g. to add or evict caches between pages.
+	BeforePage func(page int)
+}
+
+// NewServer starts a server for owner/name holding the given caches. It must be closed, and it panics when owner
+// or name are not valid.
+func NewServer(owner string, name string, caches ...types.Cache) *Server {
+	s := &Server{Store: NewStore(caches...), Owner: owner, Name: name}
+	s.Server = httptest.NewTLSServer(http.HandlerFunc(s.handle))
+	repo, err := ghRepo.ParseWithHost(fmt.Sprintf("%s/%s", owner, name), strings.TrimPrefix(s.URL, "https://"))
+	if err != nil {
+		s.Close()
+		panic(fmt.Sprintf("actionscachetest: invalid repository: %v", err))
+	}
+	s.repo = repo
+	return s
+}
+
+// Repo returns the repository served by s, with the host of the server.
+func (s *Server) Repo() ghRepo.Repository {
+	return s.repo
+}
+
+// ClientOptions returns options for an actionscache client trusting the certificate of the server.
+func (s *Server) ClientOptions() *actionscache.ClientOptions {
+	opts := actionscache.DefaultClientOptions()
+	opts.AuthToken = "test-token"
+	opts.Transport = s.Client().Transport
+	return opts
+}
+
+// NewClient returns an actionscache client for the repository served by s.
+func (s *Server) NewClient() (*actionscache.Client, error) {
+	return actionscache.NewClient(s.Repo(), s.ClientOptions())
+}
+
+func (s *Server) handle(w http.ResponseWriter, r *http.Request) {
+	match := cachesPathRE.FindStringSubmatch(r.URL.Path)
+	if match == nil || match[1] != s.Owner || match[2] != s.Name {
+		writeError(w, notFoundError())
+		return
+	}
+
+	switch {
+	case match[3] == "cache/usage" && r.Method == http.MethodGet:
+		writeJSON(w, http.StatusOK, types.RepoLevelUsageApiResponse{
+			FullName:               fmt.Sprintf("%s/%s", s.Owner, s.Name),
+			ActiveCacheSizeInBytes: float64(s.Usage()),
+			ActiveCacheCount:       float64(len(s.Caches())),
+		})
+	case match[3] == "caches" && match[4] == "" && r.Method == http.MethodGet:
+		s.list(w, r)
+	case match[3] == "caches" && match[4] == "" && r.Method == http.MethodDelete:
+		response, err := s.DeleteByKey(r.URL.Query().Get("key"), r.URL.Query().Get("ref"))
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, response)
+	case match[3] == "caches" && r.Method == http.MethodDelete:
+		id, _ := strconv.ParseInt(match[4], 10, 64)
+		if err := s.DeleteById(id); err != nil {
+			writeError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeError(w, api.HTTPError{StatusCode: http.StatusMethodNotAllowed, Message: "Method Not Allowed"})
+	}
+}
+
+func (s *Server) list(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	page, _ := intParam(query, "page", 1)
+	if s.BeforePage != nil {
+		s.BeforePage(page)
+	}
+
+	response, err := s.List(query)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	perPage, _ := intParam(query, "per_page", DEFAULT_PER_PAGE)
+	lastPage := (response.TotalCount + perPage - 1) / perPage
+	var links []string
+	if page < lastPage {
+		links = append(links, s.pageLink(r.URL, page+1, "next"), s.pageLink(r.URL, lastPage, "last"))
+	}
+	if page > 1 {
+		links = append(links, s.pageLink(r.URL, 1, "first"), s.pageLink(r.URL, page-1, "prev"))
+	}
+	if len(links) > 0 {
+		w.Header().Set("Link", strings.Join(links, ", "))
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+func (s *Server) pageLink(current *url.URL, page int, rel string) string {
+	query := current.Query()
+	query.Set("page", strconv.Itoa(page))
+	return fmt.Sprintf(`<%s%s?%s>; rel="%s"`, s.URL, current.Path, query.Encode(), rel)
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	var httpError api.HTTPError
+	if !errors.As(err, &httpError) {
+		httpError = api.HTTPError{StatusCode: http.StatusInternalServerError, Message: err.Error()}
+	}
+	writeJSON(w, httpError.StatusCode, map[string]string{"message": httpError.Message})
+}
@@ -0,0 +1,109 @@
+package actionscachetest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/actions/gh-actions-cache/pkg/actionscache"
+	"github.com/actions/gh-actions-cache/types"
+	"github.com/cli/go-gh/pkg/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testCaches() []types.Cache {
+	return []types.Cache{
+		{Id: 1, Key: "Linux-node-a", Ref: types.ParseRef("refs/heads/main"), SizeInBytes: 300, CreatedAt: june(1), LastAccessedAt: june(5)},
+		{Id: 2, Key: "Linux-node-b", Ref: types.ParseRef("refs/pull/2/merge"), SizeInBytes: 100, CreatedAt: june(2), LastAccessedAt: june(2)},
+		{Id: 3, Key: "Linux-node-b", Ref: types.ParseRef("refs/heads/main"), SizeInBytes: 200, CreatedAt: june(3), LastAccessedAt: june(4)},
+		{Id: 4, Key: "Windows-node-a", Ref: types.ParseRef("refs/heads/main"), SizeInBytes: 400, CreatedAt: june(4), LastAccessedAt: june(6)},
+	}
+}
+
+func newTestServer(t *testing.T) (*Server, *actionscache.Client) {
+	server := NewServer("testOrg", "testRepo", testCaches()...)
+	t.Cleanup(server.Close)
+	client, err := server.NewClient()
+	require.NoError(t, err)
+	return server, client
+}
+
+// june returns midnight UTC of the given day of June 2022.
+func june(day int) time.Time {
+	return time.Date(2022, time.June, day, 0, 0, 0, 0, time.UTC)
+}
+
+func ids(caches []types.Cache) []int64 {
+	result := make([]int64, 0, len(caches))
+	for _, cache := range caches {
+		result = append(result, cache.Id)
+	}
+	return result
+}
+
+func TestServer_ListFiltersSortsAndPaginates(t *testing.T) {
+	_, client := newTestServer(t)
+	ctx := context.Background()
+
+	caches, err := client.List(ctx, actionscache.ListOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, []int64{4, 1, 3, 2}, ids(caches))
+
+	caches, err = client.List(ctx, actionscache.ListOptions{Key: "Linux-", Branch: "main", Sort: actionscache.SortBySize, Direction: actionscache.Ascending})
+	require.NoError(t, err)
+	assert.Equal(t, []int64{3, 1}, ids(caches))
+
+	it, err := client.Iterate(ctx, actionscache.ListOptions{Sort: actionscache.SortByCreatedAt, Direction: actionscache.Ascending, Limit: 1})
+	require.NoError(t, err)
+	defer it.Close()
+	var iterated []int64
+	for it.Next() {
+		iterated = append(iterated, it.Cache().Id)
+	}
+	assert.NoError(t, it.Err())
+	assert.Equal(t, []int64{1, 2, 3, 4}, iterated)
+}
+
+func TestServer_UsageAndDeletes(t *testing.T) {
+	server, client := newTestServer(t)
+	ctx := context.Background()
+
+	usage, err := client.Usage(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1000), usage.ActiveCachesSizeInBytes)
+
+	deleted, err := client.DeleteByKey(ctx, "Linux-node-b", "")
+	require.NoError(t, err)
+	assert.Equal(t, []int64{2, 3}, ids(deleted))
+
+	require.NoError(t, client.DeleteByID(ctx, 4))
+	err = client.DeleteByID(ctx, 4)
+	var httpError api.HTTPError
+	if assert.ErrorAs(t, err, &httpError) {
+		assert.Equal(t, 404, httpError.StatusCode)
+	}
+
+	assert.Equal(t, []int64{1}, ids(server.Caches()))
+}
+
+func TestServer_EvictionBetweenPages(t *testing.T) {
+	server, client := newTestServer(t)
+	server.BeforePage = func(page int) {
+		if page == 2 {
+			server.EvictLeastRecentlyUsed(1)
+		}
+	}
+
+	it, err := client.Iterate(context.Background(), actionscache.ListOptions{Sort: actionscache.SortByCreatedAt, Direction: actionscache.Ascending, Limit: 2})
+	require.NoError(t, err)
+	defer it.Close()
+	var iterated []int64
+	for it.Next() {
+		iterated = append(iterated, it.Cache().Id)
+	}
+
+	assert.NoError(t, it.Err())
+	assert.Equal(t, []int64{1, 2, 4}, iterated)
+	assert.Equal(t, 3, it.TotalCount())
+}
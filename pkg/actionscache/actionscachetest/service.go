@@ -0,0 +1,132 @@
+package actionscachetest
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+	"sync"
+
+	"github.com/actions/gh-actions-cache/service"
+	"github.com/actions/gh-actions-cache/types"
+)
+
+// FakeService is an in-memory service.ArtifactCacheService backed by a Store.
+type FakeService struct {
+	*Store
+
+	mutex sync.Mutex
+	// Errors makes DeleteCacheById fail with the given error for the given id.
+	Errors map[int64]error
+	// Calls counts the calls of every method by name.
+	Calls map[string]int
+	// Login is returned by GetUserLogin.
+	Login string
+}
+
+var _ service.ArtifactCacheService = (*FakeService)(nil)
+
+func NewFakeService(caches ...types.Cache) *FakeService {
+	return &FakeService{Store: NewStore(caches...), Errors: map[int64]error{}, Calls: map[string]int{}}
+}
+
+func (f *FakeService) record(method string) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.Calls[method]++
+}
+
+func (f *FakeService) GetCacheUsage(ctx context.Context) (int64, error) {
+	f.record("GetCacheUsage")
+	if err := ctx.Err(); err != nil {
+		return -1, err
+	}
+	return f.Usage(), nil
+}
+
+func (f *FakeService) GetUserLogin(ctx context.Context) (string, error) {
+	f.record("GetUserLogin")
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	return f.Login, nil
+}
+
+func (f *FakeService) ListCaches(ctx context.Context, queryParams url.Values) (types.CacheList, error) {
+	f.record("ListCaches")
+	if err := ctx.Err(); err != nil {
+		return types.CacheList{}, err
+	}
+	return f.List(queryParams)
+}
+
+func (f *FakeService) DeleteCaches(ctx context.Context, queryParams url.Values) (types.CacheList, error) {
+	f.record("DeleteCaches")
+	if err := ctx.Err(); err != nil {
+		return types.CacheList{}, err
+	}
+	return f.DeleteByKey(queryParams.Get("key"), queryParams.Get("ref"))
+}
+
+func (f *FakeService) DeleteCacheById(ctx context.Context, id int64) error {
+	f.record("DeleteCacheById")
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	f.mutex.Lock()
+	err := f.Errors[id]
+	f.mutex.Unlock()
+	if err != nil {
+		return err
+	}
+	return f.DeleteById(id)
+}
+
+func (f *FakeService) ListAllCaches(ctx context.Context, queryParams url.Values, key string) ([]types.Cache, error) {
+	if key != "" {
+		queryParams.Set("key", key)
+	}
+	inventory, err := f.ListCacheInventory(ctx, queryParams, false)
+	return inventory.Caches, err
+}
+
+func (f *FakeService) IterateCaches(ctx context.Context, queryParams url.Values, concurrency int) *service.CacheIterator {
+	pages, err := f.listPages(ctx, queryParams)
+	return service.NewCacheIteratorFromPages(pages, err)
+}
+
+func (f *FakeService) ListCacheInventory(ctx context.Context, queryParams url.Values, rescan bool) (types.CacheInventory, error) {
+	pages, err := f.listPages(ctx, queryParams)
+	if err != nil {
+		return types.CacheInventory{}, err
+	}
+	inventory := types.CacheInventory{Scans: 1}
+	for _, page := range pages {
+		inventory.Caches = append(inventory.Caches, page.Caches...)
+		inventory.TotalCount = page.TotalCount
+	}
+	return inventory, nil
+}
+
+// listPages fetches every page of the listing, each one through ListCaches.
+func (f *FakeService) listPages(ctx context.Context, queryParams url.Values) ([]types.CacheList, error) {
+	params := url.Values{}
+	for name, values := range queryParams {
+		params[name] = values
+	}
+	perPage, err := intParam(params, "per_page", DEFAULT_PER_PAGE)
+	if err != nil {
+		return nil, validationError("per_page")
+	}
+	var pages []types.CacheList
+	for page := 1; ; page++ {
+		params.Set("page", strconv.Itoa(page))
+		response, err := f.ListCaches(ctx, params)
+		if err != nil {
+			return nil, err
+		}
+		pages = append(pages, response)
+		if len(response.Caches) < perPage || page*perPage >= response.TotalCount {
+			return pages, nil
+		}
+	}
+}
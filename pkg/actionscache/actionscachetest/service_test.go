@@ -0,0 +1,44 @@
+package actionscachetest
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"testing"
+
+	"github.com/actions/gh-actions-cache/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFakeService_ListAllCachesFollowsPages(t *testing.T) {
+	fake := NewFakeService(testCaches()...)
+
+	caches, err := fake.ListAllCaches(context.Background(), url.Values{"per_page": []string{"3"}}, "")
+
+	require.NoError(t, err)
+	assert.Equal(t, []int64{4, 1, 3, 2}, ids(caches))
+	assert.Equal(t, 2, fake.Calls["ListCaches"])
+}
+
+func TestFakeService_WithDeleter(t *testing.T) {
+	fake := NewFakeService(testCaches()...)
+	fake.Errors[2] = errors.New("boom")
+
+	report := service.Deleter{ArtifactCache: fake, Parallel: 2}.Run(context.Background(), service.DeletionJobsById(fake.Caches()))
+
+	assert.Equal(t, []int64{1, 3, 4}, ids(report.Deleted()))
+	if assert.Len(t, report.Failures(), 1) {
+		assert.Equal(t, int64(2), report.Failures()[0].Job.Cache.Id)
+	}
+	assert.Equal(t, []int64{2}, ids(fake.Caches()))
+}
+
+func TestFakeService_DeleteCachesRequiresMatch(t *testing.T) {
+	fake := NewFakeService(testCaches()...)
+
+	_, err := fake.DeleteCaches(context.Background(), url.Values{"key": []string{"Linux-node-"}})
+
+	assert.Error(t, err)
+	assert.Len(t, fake.Caches(), 4)
+}
@@ -0,0 +1,205 @@
+// Package actionscachetest provides an in-memory cache store, a fake service.ArtifactCacheService and a fake
+// GitHub cache API server built on it, for tests and demos that must not reach GitHub.
+package actionscachetest
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/actions/gh-actions-cache/types"
+	"github.com/cli/go-gh/pkg/api"
+)
+
+const DEFAULT_PER_PAGE = 30
+const MAX_PER_PAGE = 100
+
+// Store holds the caches of one repository and implements the filtering, sorting, pagination and deletion rules
+// of the GitHub cache API. It is safe for concurrent use.
+type Store struct {
+	mutex  sync.Mutex
+	caches []types.Cache
+	nextId int64
+}
+
+func NewStore(caches ...types.Cache) *Store {
+	s := &Store{nextId: 1}
+	s.Add(caches...)
+	return s
+}
+
+// Add stores the caches. Caches without an id get the next free one and caches without timestamps are created
+// and accessed now.
+func (s *Store) Add(caches ...types.Cache) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	now := time.Now().UTC()
+	for _, cache := range caches {
+		if cache.Id == 0 {
+			cache.Id = s.nextId
+		}
+		if cache.Id >= s.nextId {
+			s.nextId = cache.Id + 1
+		}
+		if cache.CreatedAt.IsZero() {
+			cache.CreatedAt = now
+		}
+		if cache.LastAccessedAt.IsZero() {
+			cache.LastAccessedAt = cache.CreatedAt
+		}
+		s.caches = append(s.caches, cache)
+	}
+}
+
+// Caches returns a copy of the stored caches in insertion order.
+func (s *Store) Caches() []types.Cache {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return append([]types.Cache(nil), s.caches...)
+}
+
+func (s *Store) Usage() int64 {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	var total int64
+	for _, cache := range s.caches {
+		total += cache.SizeInBytes
+	}
+	return total
+}
+
+// List returns the page of caches selected by the ref, key, sort, direction, per_page and page parameters.
+func (s *Store) List(query url.Values) (types.CacheList, error) {
+	perPage, err := intParam(query, "per_page", DEFAULT_PER_PAGE)
+	if err != nil || perPage < 1 || perPage > MAX_PER_PAGE {
+		return types.CacheList{}, validationError("per_page")
+	}
+	page, err := intParam(query, "page", 1)
+	if err != nil || page < 1 {
+		return types.CacheList{}, validationError("page")
+	}
+	less, ok := sortFuncs[query.Get("sort")]
+	if !ok {
+		return types.CacheList{}, validationError("sort")
+	}
+	direction := query.Get("direction")
+	if direction != "" && direction != "asc" && direction != "desc" {
+		return types.CacheList{}, validationError("direction")
+	}
+
+	s.mutex.Lock()
+	var matched []types.Cache
+	for _, cache := range s.caches {
+		if (query.Get("ref") == "" || cache.Ref.Raw == query.Get("ref")) && strings.HasPrefix(cache.Key, query.Get("key")) {
+			matched = append(matched, cache)
+		}
+	}
+	s.mutex.Unlock()
+
+	sort.SliceStable(matched, func(i, j int) bool {
+		if direction == "asc" {
+			return less(matched[i], matched[j])
+		}
+		return less(matched[j], matched[i])
+	})
+
+	response := types.CacheList{TotalCount: len(matched), Caches: []types.Cache{}}
+	start := (page - 1) * perPage
+	if start < len(matched) {
+		end := start + perPage
+		if end > len(matched) {
+			end = len(matched)
+		}
+		response.Caches = matched[start:end]
+	}
+	return response, nil
+}
+
+// DeleteByKey deletes the caches with exactly the given key, limited to ref when it is not empty. Like the API
+// it fails with a 404 when no cache matches.
+func (s *Store) DeleteByKey(key string, ref string) (types.CacheList, error) {
+	if key == "" {
+		return types.CacheList{}, validationError("key")
+	}
+	deleted := s.remove(func(cache types.Cache) bool {
+		return cache.Key == key && (ref == "" || cache.Ref.Raw == ref)
+	})
+	if len(deleted) == 0 {
+		return types.CacheList{}, notFoundError()
+	}
+	return types.CacheList{TotalCount: len(deleted), Caches: deleted}, nil
+}
+
+func (s *Store) DeleteById(id int64) error {
+	deleted := s.remove(func(cache types.Cache) bool { return cache.Id == id })
+	if len(deleted) == 0 {
+		return notFoundError()
+	}
+	return nil
+}
+
+// Evict removes the caches with the given ids, like GitHub does for caches over the storage quota.
+func (s *Store) Evict(ids ...int64) {
+	evicted := map[int64]bool{}
+	for _, id := range ids {
+		evicted[id] = true
+	}
+	s.remove(func(cache types.Cache) bool { return evicted[cache.Id] })
+}
+
+// EvictLeastRecentlyUsed removes the n least recently accessed caches and returns them.
+func (s *Store) EvictLeastRecentlyUsed(n int) []types.Cache {
+	caches := s.Caches()
+	sort.SliceStable(caches, func(i, j int) bool { return caches[i].LastAccessedAt.Before(caches[j].LastAccessedAt) })
+	if n > len(caches) {
+		n = len(caches)
+	}
+	ids := make([]int64, 0, n)
+	for _, cache := range caches[:n] {
+		ids = append(ids, cache.Id)
+	}
+	s.Evict(ids...)
+	return caches[:n]
+}
+
+func (s *Store) remove(match func(types.Cache) bool) []types.Cache {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	var kept, removed []types.Cache
+	for _, cache := range s.caches {
+		if match(cache) {
+			removed = append(removed, cache)
+		} else {
+			kept = append(kept, cache)
+		}
+	}
+	s.caches = kept
+	return removed
+}
+
+var sortFuncs = map[string]func(a, b types.Cache) bool{
+	"":                 func(a, b types.Cache) bool { return a.LastAccessedAt.Before(b.LastAccessedAt) },
+	"last_accessed_at": func(a, b types.Cache) bool { return a.LastAccessedAt.Before(b.LastAccessedAt) },
+	"created_at":       func(a, b types.Cache) bool { return a.CreatedAt.Before(b.CreatedAt) },
+	"size_in_bytes":    func(a, b types.Cache) bool { return a.SizeInBytes < b.SizeInBytes },
+}
+
+func intParam(query url.Values, name string, defaultValue int) (int, error) {
+	if query.Get(name) == "" {
+		return defaultValue, nil
+	}
+	return strconv.Atoi(query.Get(name))
+}
+
+func notFoundError() error {
+	return api.HTTPError{StatusCode: http.StatusNotFound, Message: "Not Found"}
+}
+
+func validationError(param string) error {
+	return api.HTTPError{StatusCode: http.StatusUnprocessableEntity, Message: fmt.Sprintf("Invalid value for %s", param)}
+}
@@ -0,0 +1,339 @@
+package actionscache
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/actions/gh-actions-cache/service"
+	"github.com/actions/gh-actions-cache/types"
+	ghRepo "github.com/cli/go-gh/pkg/repository"
+)
+
+const DEFAULT_USER_AGENT = "gh-actions-cache-sdk"
+
+// Cache is a cache entry with parsed timestamps and a decoded ref, it encodes to the JSON of the API.
+type Cache = types.Cache
+
+// Ref is the decoded ref of a cache, e.g. a branch name or a pull request number.
+type Ref = types.Ref
+
+type SortField string
+
+const (
+	SortByLastUsed  SortField = "last_accessed_at"
+	SortBySize      SortField = "size_in_bytes"
+	SortByCreatedAt SortField = "created_at"
+)
+
+type Direction string
+
+const (
+	Ascending  Direction = "asc"
+	Descending Direction = "desc"
+)
+
+type ClientOptions struct {
+	// AuthToken defaults to the token of the gh configuration or of the GH_TOKEN and GITHUB_TOKEN variables.
+	AuthToken string
+	// UserAgent defaults to DEFAULT_USER_AGENT.
+	UserAgent string
+	// MaxRetries is the number of retries of rate limited and failed requests, 0 means no retries.
+	MaxRetries int
+	// MaxRetryWait is the longest total wait between the retries of a request.
+	MaxRetryWait time.Duration
+	// Transport defaults to http.DefaultTransport.
+	Transport http.RoundTripper
+	// Log receives every request, the retries and the remaining rate limit when set.
+	Log io.Writer
+	// Debug also logs the request headers and the body of failed responses to Log, with tokens redacted.
+	Debug bool
+	// CacheTTL stores listings and usage on disk and revalidates them with conditional requests for that long, 0
+	// disables it.
+	CacheTTL time.Duration
+	// CacheDir defaults to gh-actions-cache in the user cache directory.
+	CacheDir string
+}
+
+func DefaultClientOptions() *ClientOptions {
+	return &ClientOptions{
+		UserAgent:    DEFAULT_USER_AGENT,
+		MaxRetries:   service.DEFAULT_MAX_RETRIES,
+		MaxRetryWait: service.DEFAULT_MAX_RETRY_WAIT,
+	}
+}
+
+// ListOptions filters and orders a listing. The zero value lists every cache of the repository.
+type ListOptions struct {
+	// Key matches the caches whose key starts with it.
+	Key string
+	// Ref matches the caches of a full ref like refs/heads/main or refs/pull/2/merge.
+	Ref string
+	// Branch is a shorthand for Ref: refs/heads/<Branch>. It is ignored when Ref is set.
+	Branch    string
+	Sort      SortField
+	Direction Direction
+	// Limit is the maximum number of caches returned, 0 means all of them.
+	Limit int
+}
+
+func (o ListOptions) Validate() error {
+	switch o.Sort {
+	case "", SortByLastUsed, SortBySize, SortByCreatedAt:
+	default:
+		return fmt.Errorf("invalid sort %q", o.Sort)
+	}
+	switch o.Direction {
+	case "", Ascending, Descending:
+	default:
+		return fmt.Errorf("invalid direction %q", o.Direction)
+	}
+	if o.Limit < 0 {
+		return fmt.Errorf("invalid limit %d", o.Limit)
+	}
+	return nil
+}
+
+func (o ListOptions) queryParams() url.Values {
+	query := url.Values{}
+	if ref := o.ref(); ref != "" {
+		query.Set("ref", ref)
+	}
+	if o.Key != "" {
+		query.Set("key", o.Key)
+	}
+	if o.Sort != "" {
+		query.Set("sort", string(o.Sort))
+	}
+	if o.Direction != "" {
+		query.Set("direction", string(o.Direction))
+	}
+	perPage := 100
+	if o.Limit > 0 && o.Limit < perPage {
+		perPage = o.Limit
+	}
+	query.Set("per_page", strconv.Itoa(perPage))
+	return query
+}
+
+func (o ListOptions) ref() string {
+	if o.Ref != "" || o.Branch == "" {
+		return o.Ref
+	}
+	if strings.HasPrefix(o.Branch, "refs/") {
+		return o.Branch
+	}
+	return "refs/heads/" + o.Branch
+}
+
+type DeleteOptions struct {
+	// Parallel is the number of concurrent delete requests, it defaults to 1.
+	Parallel int
+	// Progress is called after every deletion with the number of finished and total deletions.
+	Progress func(done int, total int)
+}
+
+type Usage struct {
+	ActiveCachesSizeInBytes int64
+}
+
+// DeletionResult is the outcome of the deletion of one cache.
+type DeletionResult struct {
+	Cache Cache
+	// Deleted is set when the cache was deleted.
+	Deleted bool
+	Err     error
+	// Skipped is set for the caches whose deletion was not started because the context was done.
+	Skipped bool
+}
+
+// DeletionReport holds the outcome of DeleteCaches, one result per cache in the order of the caches.
+type DeletionReport struct {
+	Results []DeletionResult
+}
+
+func newDeletionReport(report service.DeletionReport) DeletionReport {
+	results := make([]DeletionResult, 0, len(report.Results))
+	for _, result := range report.Results {
+		results = append(results, DeletionResult{Cache: result.Job.Cache, Deleted: len(result.Deleted) > 0, Err: result.Err, Skipped: result.Skipped})
+	}
+	return DeletionReport{Results: results}
+}
+
+func (r DeletionReport) Deleted() []Cache {
+	var deleted []Cache
+	for _, result := range r.Results {
+		if result.Deleted {
+			deleted = append(deleted, result.Cache)
+		}
+	}
+	return deleted
+}
+
+func (r DeletionReport) Failures() []DeletionResult {
+	var failures []DeletionResult
+	for _, result := range r.Results {
+		if result.Err != nil && !result.Skipped {
+			failures = append(failures, result)
+		}
+	}
+	return failures
+}
+
+func (r DeletionReport) Skipped() []DeletionResult {
+	var skipped []DeletionResult
+	for _, result := range r.Results {
+		if result.Skipped {
+			skipped = append(skipped, result)
+		}
+	}
+	return skipped
+}
+
+func (r DeletionReport) BytesReclaimed() int64 {
+	var total int64
+	for _, cache := range r.Deleted() {
+		total += cache.SizeInBytes
+	}
+	return total
+}
+
+// CacheIterator goes over the caches of a listing, fetching the pages as they are consumed.
+type CacheIterator struct {
+	it *service.CacheIterator
+}
+
+// Next advances to the next cache and reports whether there is one. It returns false at the end of the listing
+// or on the first error, which is then available through Err.
+func (it *CacheIterator) Next() bool {
+	return it.it.Next()
+}
+
+func (it *CacheIterator) Cache() Cache {
+	return it.it.Cache()
+}
+
+// TotalCount returns the total count reported by the most recent page, which can change during the iteration.
+func (it *CacheIterator) TotalCount() int {
+	return it.it.TotalCount()
+}
+
+func (it *CacheIterator) Err() error {
+	return it.it.Err()
+}
+
+// Close stops fetching pages. It must be called when the iteration is abandoned before Next returns false.
+func (it *CacheIterator) Close() {
+	it.it.Close()
+}
+
+// Client manages the caches of one repository. It is safe for concurrent use.
+type Client struct {
+	repo          ghRepo.Repository
+	artifactCache *service.ArtifactCache
+}
+
+// NewClient returns a client for repo. A nil opts uses DefaultClientOptions.
+func NewClient(repo ghRepo.Repository, opts *ClientOptions) (*Client, error) {
+	if opts == nil {
+		opts = DefaultClientOptions()
+	}
+	userAgent := opts.UserAgent
+	if userAgent == "" {
+		userAgent = DEFAULT_USER_AGENT
+	}
+	artifactCache, err := service.NewArtifactCacheWithOptions(repo, service.ArtifactCacheOptions{
+		UserAgent: userAgent,
+		AuthToken: opts.AuthToken,
+		Retry:     service.RetryOptions{MaxRetries: opts.MaxRetries, MaxWait: opts.MaxRetryWait, Verbose: opts.Log != nil, Debug: opts.Debug && opts.Log != nil},
+		Transport: opts.Transport,
+		Log:       opts.Log,
+		Cache:     service.ResponseCacheOptions{TTL: opts.CacheTTL, Dir: opts.CacheDir},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &Client{repo: repo, artifactCache: artifactCache}, nil
+}
+
+// NewClientForRepo returns a client for a repository in the [HOST/]OWNER/REPO format.
+func NewClientForRepo(fullName string, opts *ClientOptions) (*Client, error) {
+	repo, err := ghRepo.Parse(fullName)
+	if err != nil {
+		return nil, err
+	}
+	return NewClient(repo, opts)
+}
+
+func (c *Client) Repo() ghRepo.Repository {
+	return c.repo
+}
+
+func (c *Client) Usage(ctx context.Context) (Usage, error) {
+	size, err := c.artifactCache.GetCacheUsage(ctx)
+	if err != nil {
+		return Usage{}, err
+	}
+	return Usage{ActiveCachesSizeInBytes: size}, nil
+}
+
+// List returns the caches matching opts, following every page up to opts.Limit.
+func (c *Client) List(ctx context.Context, opts ListOptions) ([]Cache, error) {
+	it, err := c.Iterate(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer it.Close()
+
+	var caches []Cache
+	seen := map[int64]bool{}
+	for (opts.Limit == 0 || len(caches) < opts.Limit) && it.Next() {
+		cache := it.Cache()
+		if !seen[cache.Id] {
+			seen[cache.Id] = true
+			caches = append(caches, cache)
+		}
+	}
+	return caches, it.Err()
+}
+
+// Iterate returns an iterator over the caches matching opts that fetches the pages as they are consumed. The
+// iterator must be closed. opts.Limit is not applied.
+func (c *Client) Iterate(ctx context.Context, opts ListOptions) (*CacheIterator, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	return &CacheIterator{it: c.artifactCache.IterateCaches(ctx, opts.queryParams(), 1)}, nil
+}
+
+// DeleteByKey deletes every cache with exactly the given key, limited to ref when it is not empty, and returns
+// the deleted caches.
+func (c *Client) DeleteByKey(ctx context.Context, key string, ref string) ([]Cache, error) {
+	if key == "" {
+		return nil, fmt.Errorf("key must not be empty")
+	}
+	query := url.Values{"key": []string{key}}
+	if ref != "" {
+		query.Set("ref", ref)
+	}
+	response, err := c.artifactCache.DeleteCaches(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	return response.Caches, nil
+}
+
+func (c *Client) DeleteByID(ctx context.Context, id int64) error {
+	return c.artifactCache.DeleteCacheById(ctx, id)
+}
+
+// DeleteCaches deletes the given caches by id. Failures are collected in the report and don't stop the others.
+func (c *Client) DeleteCaches(ctx context.Context, caches []Cache, opts DeleteOptions) DeletionReport {
+	deleter := service.Deleter{ArtifactCache: c.artifactCache, Parallel: opts.Parallel, Progress: opts.Progress}
+	return newDeletionReport(deleter.Run(ctx, service.DeletionJobsById(caches)))
+}
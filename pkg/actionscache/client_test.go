@@ -0,0 +1,108 @@
+package actionscache
+
+import (
+	"context"
+	"testing"
+
+	"github.com/actions/gh-actions-cache/internal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/h2non/gock.v1"
+)
+
+func newTestClient(t *testing.T) *Client {
+	client, err := NewClientForRepo("testOrg/testRepo", &ClientOptions{AuthToken: "token"})
+	require.NoError(t, err)
+	return client
+}
+
+func TestNewClientForRepo_InvalidRepo(t *testing.T) {
+	client, err := NewClientForRepo("testOrg", nil)
+
+	assert.Error(t, err)
+	assert.Nil(t, client)
+}
+
+func TestUsage(t *testing.T) {
+	t.Cleanup(gock.Off)
+
+	gock.New("https://api.github.com").
+		Get("/repos/testOrg/testRepo/actions/cache/usage").
+		MatchHeader("Authorization", "token token").
+		MatchHeader("User-Agent", DEFAULT_USER_AGENT).
+		Reply(200).
+		JSON(`{"full_name": "testOrg/testRepo", "active_caches_size_in_bytes": 291205, "active_caches_count": 12}`)
+
+	usage, err := newTestClient(t).Usage(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, Usage{ActiveCachesSizeInBytes: 291205}, usage)
+	assert.True(t, gock.IsDone(), internal.PrintPendingMocks(gock.Pending()))
+}
+
+func TestList_FiltersAndLimits(t *testing.T) {
+	t.Cleanup(gock.Off)
+
+	gock.New("https://api.github.com").
+		Get("/repos/testOrg/testRepo/actions/caches").
+		MatchParam("key", "Linux-node-").
+		MatchParam("ref", "refs/heads/main").
+		MatchParam("sort", "size_in_bytes").
+		MatchParam("direction", "desc").
+		MatchParam("per_page", "2").
+		Reply(200).
+		SetHeader("Link", `<https://api.github.com/repositories/1/actions/caches?per_page=2&page=2>; rel="next"`).
+		JSON(`{"total_count": 3, "actions_caches": [{"id": 1, "key": "Linux-node-1"}, {"id": 2, "key": "Linux-node-2"}]}`)
+
+	caches, err := newTestClient(t).List(context.Background(), ListOptions{Key: "Linux-node-", Branch: "main", Sort: SortBySize, Direction: Descending, Limit: 2})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []Cache{{Id: 1, Key: "Linux-node-1"}, {Id: 2, Key: "Linux-node-2"}}, caches)
+	assert.True(t, gock.IsDone(), internal.PrintPendingMocks(gock.Pending()))
+}
+
+func TestList_InvalidSort(t *testing.T) {
+	_, err := newTestClient(t).List(context.Background(), ListOptions{Sort: "name"})
+
+	assert.EqualError(t, err, `invalid sort "name"`)
+}
+
+func TestDeleteByKey(t *testing.T) {
+	t.Cleanup(gock.Off)
+
+	gock.New("https://api.github.com").
+		Delete("/repos/testOrg/testRepo/actions/caches").
+		MatchParam("key", "Linux-node-1").
+		MatchParam("ref", "refs/pull/2/merge").
+		Reply(200).
+		JSON(`{"total_count": 1, "actions_caches": [{"id": 1, "key": "Linux-node-1", "ref": "refs/pull/2/merge"}]}`)
+
+	deleted, err := newTestClient(t).DeleteByKey(context.Background(), "Linux-node-1", "refs/pull/2/merge")
+
+	assert.NoError(t, err)
+	if assert.Len(t, deleted, 1) {
+		assert.Equal(t, int64(1), deleted[0].Id)
+		assert.Equal(t, "refs/pull/2/merge", deleted[0].Ref.Raw)
+		assert.Equal(t, 2, deleted[0].Ref.PullRequest)
+		assert.Equal(t, "merge", deleted[0].Ref.PullRequestRef)
+	}
+	assert.True(t, gock.IsDone(), internal.PrintPendingMocks(gock.Pending()))
+}
+
+func TestDeleteCaches(t *testing.T) {
+	t.Cleanup(gock.Off)
+
+	gock.New("https://api.github.com").
+		Delete("/repos/testOrg/testRepo/actions/caches/1").
+		Reply(204)
+	gock.New("https://api.github.com").
+		Delete("/repos/testOrg/testRepo/actions/caches/2").
+		Reply(204)
+
+	report := newTestClient(t).DeleteCaches(context.Background(), []Cache{{Id: 1, SizeInBytes: 10}, {Id: 2, SizeInBytes: 20}}, DeleteOptions{Parallel: 2})
+
+	assert.Empty(t, report.Failures())
+	assert.Equal(t, []Cache{{Id: 1, SizeInBytes: 10}, {Id: 2, SizeInBytes: 20}}, report.Deleted())
+	assert.Equal(t, int64(30), report.BytesReclaimed())
+	assert.True(t, gock.IsDone(), internal.PrintPendingMocks(gock.Pending()))
+}
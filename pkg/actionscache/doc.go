@@ -0,0 +1,16 @@
+// Package actionscache manages the GitHub Actions caches of a repository. It is the library behind the
+// gh actions-cache extension, without the command line and terminal printing.
+//
+//	client, err := actionscache.NewClientForRepo("octo-org/octo-repo", nil)
+//	if err != nil {
+//		return err
+//	}
+//	caches, err := client.List(ctx, actionscache.ListOptions{Key: "Linux-node-", Sort: actionscache.SortBySize})
+//	if err != nil {
+//		return err
+//	}
+//	report := client.DeleteCaches(ctx, caches, actionscache.DeleteOptions{Parallel: 4})
+//
+// Clients authenticate like gh: with the token of the gh configuration or of the GH_TOKEN and GITHUB_TOKEN
+// variables unless ClientOptions.AuthToken is set. Rate limited and failed requests are retried with backoff.
+package actionscache
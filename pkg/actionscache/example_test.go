@@ -0,0 +1,23 @@
+package actionscache_test
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/actions/gh-actions-cache/pkg/actionscache"
+)
+
+func Example() {
+	ctx := context.Background()
+	client, err := actionscache.NewClientForRepo("octo-org/octo-repo", nil)
+	if err != nil {
+		panic(err)
+	}
+
+	caches, err := client.List(ctx, actionscache.ListOptions{Branch: "feature", Sort: actionscache.SortByLastUsed, Direction: actionscache.Ascending})
+	if err != nil {
+		panic(err)
+	}
+	report := client.DeleteCaches(ctx, caches, actionscache.DeleteOptions{Parallel: 4})
+	fmt.Printf("deleted %d caches, %d failures\n", len(report.Deleted()), len(report.Failures()))
+}
@@ -1,10 +1,12 @@
 package service
 
 import (
+	"context"
 	"fmt"
-	"math"
+	"io"
+	"net/http"
 	"net/url"
-	"strconv"
+	"time"
 
 	"github.com/actions/gh-actions-cache/types"
 	gh "github.com/cli/go-gh"
@@ -13,21 +15,75 @@ import (
 )
 
 type ArtifactCacheService interface {
-	GetCacheUsage() (float64, error)
-	ListCaches(queryParams url.Values) (types.ListApiResponse, error)
-	DeleteCaches(queryParams url.Values) (int, error)
-	ListAllCaches(queryParams url.Values, key string) ([]types.ActionsCache, error)
+	GetCacheUsage(ctx context.Context) (int64, error)
+	ListCaches(ctx context.Context, queryParams url.Values) (types.CacheList, error)
+	DeleteCaches(ctx context.Context, queryParams url.Values) (types.CacheList, error)
+	DeleteCacheById(ctx context.Context, id int64) error
+	ListAllCaches(ctx context.Context, queryParams url.Values, key string) ([]types.Cache, error)
+	IterateCaches(ctx context.Context, queryParams url.Values, concurrency int) *CacheIterator
+	ListCacheInventory(ctx context.Context, queryParams url.Values, rescan bool) (types.CacheInventory, error)
+	GetUserLogin(ctx context.Context) (string, error)
 }
 
+const MAX_INVENTORY_RESCANS = 2
+
 type ArtifactCache struct {
 	HttpClient api.RESTClient
 	repo       ghRepo.Repository
 }
 
+// ArtifactCacheOptions configures the client of NewArtifactCacheWithOptions.
+type ArtifactCacheOptions struct {
+	UserAgent string
+	// AuthToken defaults to the token of the gh configuration or of the GH_TOKEN and GITHUB_TOKEN variables.
+	AuthToken string
+	Retry     RetryOptions
+	// Transport defaults to http.DefaultTransport.
+	Transport http.RoundTripper
+	// Log receives the verbose and debug output, it defaults to stderr.
+	Log io.Writer
+	// Cache enables conditional requests answered from responses stored on disk.
+	Cache ResponseCacheOptions
+}
+
 func NewArtifactCache(repo ghRepo.Repository, command string, version string) (ArtifactCacheService, error) {
+	artifactCache, err := NewArtifactCacheWithOptions(repo, ArtifactCacheOptions{
+		UserAgent: fmt.Sprintf("gh-actions-cache/%s/%s", version, command),
+		Retry:     HttpOptions,
+		Cache:     ResponseCache,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return artifactCache, nil
+}
+
+func NewArtifactCacheWithOptions(repo ghRepo.Repository, options ArtifactCacheOptions) (*ArtifactCache, error) {
+	transport := NewRetryTransport(options.Retry)
+	transport.Base = options.Transport
+	if options.Log != nil {
+		transport.Log = options.Log
+	}
+	if options.Retry.Verbose || options.Retry.Debug {
+		transport.Options.Verbose = true
+		transport.Base = &TraceTransport{Base: transport.Base, Log: transport.Log, Debug: options.Retry.Debug, Now: time.Now}
+	}
+	if options.Cache.Enabled() {
+		dir := options.Cache.Dir
+		if dir == "" {
+			var err error
+			if dir, err = DefaultResponseCacheDir(); err != nil {
+				return nil, err
+			}
+		}
+		// Below the retries and above the tracing, so that every attempt is conditional and 304s are logged.
+		transport.Base = &ETagTransport{Base: transport.Base, Dir: dir, TTL: options.Cache.TTL, Now: time.Now}
+	}
 	opts := api.ClientOptions{
-		Host:    repo.Host(),
-		Headers: map[string]string{"User-Agent": fmt.Sprintf("gh-actions-cache/%s/%s", version, command)},
+		Host:      repo.Host(),
+		AuthToken: options.AuthToken,
+		Headers:   map[string]string{"User-Agent": options.UserAgent},
+		Transport: transport,
 	}
 	restClient, err := gh.RESTClient(&opts)
 	if err != nil {
@@ -36,57 +92,118 @@ func NewArtifactCache(repo ghRepo.Repository, command string, version string) (A
 	return &ArtifactCache{HttpClient: restClient, repo: repo}, nil
 }
 
-func (a *ArtifactCache) GetCacheUsage() (float64, error) {
+func (a *ArtifactCache) GetCacheUsage(ctx context.Context) (int64, error) {
 	pathComponent := fmt.Sprintf("repos/%s/%s/actions/cache/usage", a.repo.Owner(), a.repo.Name())
 	var apiResults types.RepoLevelUsageApiResponse
-	err := a.HttpClient.Get(pathComponent, &apiResults)
+	err := a.HttpClient.DoWithContext(ctx, http.MethodGet, pathComponent, nil, &apiResults)
 	if err != nil {
 		return -1, err
 	}
 
-	return apiResults.ActiveCacheSizeInBytes, nil
+	return int64(apiResults.ActiveCacheSizeInBytes), nil
 }
 
-func (a *ArtifactCache) ListCaches(queryParams url.Values) (types.ListApiResponse, error) {
+// GetUserLogin returns the login of the user owning the token. Tokens of GitHub Apps such as the GITHUB_TOKEN of
+// workflows are not allowed to read it.
+func (a *ArtifactCache) GetUserLogin(ctx context.Context) (string, error) {
+	var user struct {
+		Login string `json:"login"`
+	}
+	err := a.HttpClient.DoWithContext(ctx, http.MethodGet, "user", nil, &user)
+	return user.Login, err
+}
+
+func (a *ArtifactCache) ListCaches(ctx context.Context, queryParams url.Values) (types.CacheList, error) {
 	pathComponent := fmt.Sprintf("repos/%s/%s/actions/caches", a.repo.Owner(), a.repo.Name())
 	var apiResults types.ListApiResponse
-	err := a.HttpClient.Get(pathComponent+"?"+queryParams.Encode(), &apiResults)
+	err := a.HttpClient.DoWithContext(ctx, http.MethodGet, pathComponent+"?"+queryParams.Encode(), nil, &apiResults)
 
 	if err != nil {
-		return types.ListApiResponse{}, err
+		return types.CacheList{}, err
 	}
 
-	return apiResults, nil
+	return newCacheList(apiResults.TotalCount, apiResults.ActionsCaches)
 }
 
-func (a *ArtifactCache) DeleteCaches(queryParams url.Values) (int, error) {
+func (a *ArtifactCache) DeleteCaches(ctx context.Context, queryParams url.Values) (types.CacheList, error) {
 	pathComponent := fmt.Sprintf("repos/%s/%s/actions/caches", a.repo.Owner(), a.repo.Name())
 	var apiResults types.DeleteApiResponse
-	err := a.HttpClient.Delete(pathComponent+"?"+queryParams.Encode(), &apiResults)
+	err := a.HttpClient.DoWithContext(ctx, http.MethodDelete, pathComponent+"?"+queryParams.Encode(), nil, &apiResults)
 	if err != nil {
-		return 0, err
+		return types.CacheList{}, err
 	}
-	return apiResults.TotalCount, nil
+	return newCacheList(apiResults.TotalCount, apiResults.ActionsCaches)
 }
 
-func (a *ArtifactCache) ListAllCaches(queryParams url.Values, key string) ([]types.ActionsCache, error) {
-	var listApiResponse types.ListApiResponse
-	listApiResponse, err := a.ListCaches(queryParams)
+func (a *ArtifactCache) DeleteCacheById(ctx context.Context, id int64) error {
+	pathComponent := fmt.Sprintf("repos/%s/%s/actions/caches/%d", a.repo.Owner(), a.repo.Name(), id)
+	return a.HttpClient.DoWithContext(ctx, http.MethodDelete, pathComponent, nil, nil)
+}
+
+// ListAllCaches fetches every page of the listing, filtered by key when it is not empty, and drops the entries
+// repeated across pages. When asked to stop it returns types.ErrInterrupted after the pages being fetched.
+func (a *ArtifactCache) ListAllCaches(ctx context.Context, queryParams url.Values, key string) ([]types.Cache, error) {
+	if key != "" {
+		queryParams.Set("key", key)
+	}
+	inventory, err := a.ListCacheInventory(ctx, queryParams, false)
 	if err != nil {
 		return nil, err
 	}
+	return inventory.Caches, nil
+}
 
-	caches := listApiResponse.ActionsCaches
-	totalCaches := listApiResponse.TotalCount
-	if totalCaches > 100 {
-		for page := 2; page <= int(math.Ceil(float64(listApiResponse.TotalCount)/100)); page++ {
-			queryParams.Set("page", strconv.Itoa(page))
-			listApiResponse, err := a.ListCaches(queryParams)
-			if err != nil {
-				return nil, err
-			}
-			caches = append(caches, listApiResponse.ActionsCaches...)
+// ListCacheInventory fetches every page of the listing deduplicated by id. With rescan, a listing whose length
+// does not match the total count is fetched again, up to MAX_INVENTORY_RESCANS times, sorted by creation date so
+// that new entries land on the last page instead of shifting the others.
+func (a *ArtifactCache) ListCacheInventory(ctx context.Context, queryParams url.Values, rescan bool) (types.CacheInventory, error) {
+	inventory, err := a.scanInventory(ctx, queryParams)
+	if err != nil || !rescan {
+		return inventory, err
+	}
+
+	stableParams := url.Values{}
+	for name, values := range queryParams {
+		stableParams[name] = values
+	}
+	stableParams.Set("sort", "created_at")
+	stableParams.Set("direction", "asc")
+	for scans := 1; !inventory.IsConsistent() && scans <= MAX_INVENTORY_RESCANS; scans++ {
+		inventory, err = a.scanInventory(ctx, stableParams)
+		if err != nil {
+			return inventory, err
 		}
+		inventory.Scans = scans + 1
+	}
+	return inventory, nil
+}
+
+func (a *ArtifactCache) scanInventory(ctx context.Context, queryParams url.Values) (types.CacheInventory, error) {
+	inventory := types.CacheInventory{Scans: 1}
+	seen := map[int64]bool{}
+	it := a.IterateCaches(ctx, queryParams, DEFAULT_PAGE_CONCURRENCY)
+	defer it.Close()
+	for it.Next() {
+		cache := it.Cache()
+		if seen[cache.Id] {
+			inventory.Duplicates++
+			continue
+		}
+		seen[cache.Id] = true
+		inventory.Caches = append(inventory.Caches, cache)
+	}
+	if it.Err() != nil {
+		return types.CacheInventory{}, it.Err()
+	}
+	inventory.TotalCount = it.TotalCount()
+	return inventory, nil
+}
+
+// newCacheList converts the caches of an API response, which is the only place where API values are parsed.
+func newCacheList(totalCount int, raw []types.ActionsCache) (types.CacheList, error) {
+	caches, err := types.NewCaches(raw)
+	if err != nil {
+		return types.CacheList{}, err
 	}
-	return caches, nil
+	return types.CacheList{TotalCount: totalCount, Caches: caches}, nil
 }
@@ -1,6 +1,8 @@
 package service
 
 import (
+	"context"
+	"net/http"
 	"net/url"
 	"testing"
 
@@ -32,10 +34,10 @@ func TestGetCacheUsage_CorrectRepo(t *testing.T) {
 	artifactCache, err := NewArtifactCache(repo, "list", VERSION)
 	require.NoError(t, err)
 	require.NotNil(t, artifactCache)
-	totalCacheSize, err := artifactCache.GetCacheUsage()
+	totalCacheSize, err := artifactCache.GetCacheUsage(context.Background())
 
 	assert.NoError(t, err)
-	assert.Equal(t, float64(291205), totalCacheSize)
+	assert.Equal(t, int64(291205), totalCacheSize)
 	assert.True(t, gock.IsDone(), internal.PrintPendingMocks(gock.Pending()))
 }
 
@@ -56,13 +58,13 @@ func TestGetCacheUsage_IncorrectRepo(t *testing.T) {
 	artifactCache, err := NewArtifactCache(repo, "list", VERSION)
 	require.NoError(t, err)
 	require.NotNil(t, artifactCache)
-	totalCacheSize, err := artifactCache.GetCacheUsage()
+	totalCacheSize, err := artifactCache.GetCacheUsage(context.Background())
 	var httpError api.HTTPError
 	if assert.ErrorAs(t, err, &httpError) {
 		assert.Equal(t, 404, httpError.StatusCode)
 		assert.Equal(t, "Not Found", httpError.Message)
 	}
-	assert.Equal(t, float64(-1), totalCacheSize)
+	assert.Equal(t, int64(-1), totalCacheSize)
 	assert.True(t, gock.IsDone(), internal.PrintPendingMocks(gock.Pending()))
 }
 
@@ -96,13 +98,13 @@ func TestListCaches_Success(t *testing.T) {
 	artifactCache, err := NewArtifactCache(repo, "list", VERSION)
 	require.NoError(t, err)
 	require.NotNil(t, artifactCache)
-	listCacheResponse, err := artifactCache.ListCaches(queryParams)
+	listCacheResponse, err := artifactCache.ListCaches(context.Background(), queryParams)
 
 	assert.NoError(t, err)
 	if assert.NotNil(t, listCacheResponse) {
 		assert.Equal(t, 1, listCacheResponse.TotalCount)
-		assert.Equal(t, 1, len(listCacheResponse.ActionsCaches))
-		assert.Equal(t, 29, listCacheResponse.ActionsCaches[0].Id)
+		assert.Equal(t, 1, len(listCacheResponse.Caches))
+		assert.Equal(t, int64(29), listCacheResponse.Caches[0].Id)
 	}
 	assert.True(t, gock.IsDone(), internal.PrintPendingMocks(gock.Pending()))
 }
@@ -128,13 +130,13 @@ func TestListCaches_Failure(t *testing.T) {
 	artifactCache, err := NewArtifactCache(repo, "list", VERSION)
 	require.NoError(t, err)
 	require.NotNil(t, artifactCache)
-	listCacheResponse, err := artifactCache.ListCaches(queryParams)
+	listCacheResponse, err := artifactCache.ListCaches(context.Background(), queryParams)
 	var httpError api.HTTPError
 	if assert.ErrorAs(t, err, &httpError) {
 		assert.Equal(t, 404, httpError.StatusCode)
 		assert.Equal(t, "Not Found", httpError.Message)
 	}
-	assert.Equal(t, types.ListApiResponse{}, listCacheResponse)
+	assert.Equal(t, types.CacheList{}, listCacheResponse)
 	assert.True(t, gock.IsDone(), internal.PrintPendingMocks(gock.Pending()))
 }
 
@@ -168,10 +170,13 @@ func TestDeleteCaches_Success(t *testing.T) {
 	artifactCache, err := NewArtifactCache(repo, "delete", VERSION)
 	require.NoError(t, err)
 	require.NotNil(t, artifactCache)
-	deletedCache, err := artifactCache.DeleteCaches(queryParams)
+	deleteCacheResponse, err := artifactCache.DeleteCaches(context.Background(), queryParams)
 
 	assert.NoError(t, err)
-	assert.Equal(t, 1, deletedCache)
+	assert.Equal(t, 1, deleteCacheResponse.TotalCount)
+	if assert.Len(t, deleteCacheResponse.Caches, 1) {
+		assert.Equal(t, int64(29), deleteCacheResponse.Caches[0].Id)
+	}
 	assert.True(t, gock.IsDone(), internal.PrintPendingMocks(gock.Pending()))
 }
 
@@ -196,9 +201,95 @@ func TestDeleteCaches_Failure(t *testing.T) {
 	artifactCache, err := NewArtifactCache(repo, "delete", VERSION)
 	require.NoError(t, err)
 	require.NotNil(t, artifactCache)
-	deletedCache, err := artifactCache.DeleteCaches(queryParams)
+	deleteCacheResponse, err := artifactCache.DeleteCaches(context.Background(), queryParams)
 
 	assert.Error(t, err)
-	assert.Equal(t, 0, deletedCache)
+	assert.Equal(t, types.CacheList{}, deleteCacheResponse)
+	assert.True(t, gock.IsDone(), internal.PrintPendingMocks(gock.Pending()))
+}
+
+func TestDeleteCacheById_Success(t *testing.T) {
+	t.Cleanup(gock.Off)
+
+	gock.New("https://api.github.com").
+		Delete("/repos/testOrg/testRepo/actions/caches/29").
+		Reply(204)
+
+	repo, err := internal.GetRepo("testOrg/testRepo")
+	require.NoError(t, err)
+
+	artifactCache, err := NewArtifactCache(repo, "delete", VERSION)
+	require.NoError(t, err)
+	require.NotNil(t, artifactCache)
+	err = artifactCache.DeleteCacheById(context.Background(), 29)
+
+	assert.NoError(t, err)
 	assert.True(t, gock.IsDone(), internal.PrintPendingMocks(gock.Pending()))
 }
+
+func TestDeleteCacheById_Failure(t *testing.T) {
+	t.Cleanup(gock.Off)
+
+	gock.New("https://api.github.com").
+		Delete("/repos/testOrg/testRepo/actions/caches/29").
+		Reply(404).
+		JSON(`{
+			"message": "Not Found",
+			"documentation_url": "https://docs.github.com/rest/actions/cache#delete-a-github-actions-cache-for-a-repository-using-a-cache-id"
+		}`)
+
+	repo, err := internal.GetRepo("testOrg/testRepo")
+	require.NoError(t, err)
+
+	artifactCache, err := NewArtifactCache(repo, "delete", VERSION)
+	require.NoError(t, err)
+	require.NotNil(t, artifactCache)
+	err = artifactCache.DeleteCacheById(context.Background(), 29)
+
+	var httpError api.HTTPError
+	if assert.ErrorAs(t, err, &httpError) {
+		assert.Equal(t, 404, httpError.StatusCode)
+	}
+	assert.True(t, gock.IsDone(), internal.PrintPendingMocks(gock.Pending()))
+}
+
+func TestListAllCaches_StopsBeforeNextPage(t *testing.T) {
+	t.Cleanup(gock.Off)
+
+	stop := make(chan struct{})
+	gock.New("https://api.github.com").
+		Get("/repos/testOrg/testRepo/actions/caches").
+		Reply(200).
+		Map(func(resp *http.Response) *http.Response {
+			close(stop)
+			return resp
+		}).
+		JSON(`{"total_count": 250, "actions_caches": [{"id": 1, "key": "keyOne"}]}`)
+
+	repo, err := internal.GetRepo("testOrg/testRepo")
+	require.NoError(t, err)
+	artifactCache, err := NewArtifactCache(repo, "list", VERSION)
+	require.NoError(t, err)
+
+	ctx := WithStop(context.Background(), stop)
+	caches, err := artifactCache.ListAllCaches(ctx, url.Values{"per_page": []string{"100"}}, "")
+
+	assert.ErrorIs(t, err, types.ErrInterrupted)
+	assert.Nil(t, caches)
+	assert.True(t, gock.IsDone(), internal.PrintPendingMocks(gock.Pending()))
+}
+
+func TestListCaches_CancelledContext(t *testing.T) {
+	t.Cleanup(gock.Off)
+
+	repo, err := internal.GetRepo("testOrg/testRepo")
+	require.NoError(t, err)
+	artifactCache, err := NewArtifactCache(repo, "list", VERSION)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = artifactCache.ListCaches(ctx, url.Values{})
+
+	assert.ErrorIs(t, err, context.Canceled)
+}
@@ -0,0 +1,31 @@
+package service
+
+import (
+	"context"
+
+	"github.com/actions/gh-actions-cache/types"
+)
+
+type stopKey struct{}
+
+// WithStop returns a context carrying stop, a channel that is closed to ask long running operations to stop
+// before they start their next request. Unlike cancelling the context, requests already in flight are completed.
+func WithStop(ctx context.Context, stop <-chan struct{}) context.Context {
+	return context.WithValue(ctx, stopKey{}, stop)
+}
+
+// Stopping returns the stop channel of ctx, or nil when there is none.
+func Stopping(ctx context.Context) <-chan struct{} {
+	stop, _ := ctx.Value(stopKey{}).(<-chan struct{})
+	return stop
+}
+
+// CheckStopped returns types.ErrInterrupted once ctx was asked to stop and the error of ctx once it is done.
+func CheckStopped(ctx context.Context) error {
+	select {
+	case <-Stopping(ctx):
+		return types.ErrInterrupted
+	default:
+	}
+	return ctx.Err()
+}
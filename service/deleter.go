@@ -0,0 +1,173 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"sync"
+
+	"github.com/actions/gh-actions-cache/types"
+	"github.com/cli/go-gh/pkg/api"
+)
+
+// DeletionJob deletes either the single cache entry Cache by its id or, when Query is set, every cache entry
+// matching the key and ref in Query.
+type DeletionJob struct {
+	Cache types.Cache
+	Query url.Values
+}
+
+func (j DeletionJob) IsKey() bool {
+	return j.Query != nil
+}
+
+type DeletionResult struct {
+	Job     DeletionJob
+	Deleted []types.Cache
+	Err     error
+	// Skipped is set for jobs that were not started because the context was stopped or done.
+	Skipped bool
+	// Gone is set for caches by id that were already deleted or evicted, with Deleter.IgnoreNotFound.
+	Gone bool
+}
+
+// DeletionReport holds one result per job, in the order of the jobs.
+type DeletionReport struct {
+	Results []DeletionResult
+}
+
+func (r DeletionReport) Deleted() []types.Cache {
+	var deleted []types.Cache
+	for _, result := range r.Results {
+		deleted = append(deleted, result.Deleted...)
+	}
+	return deleted
+}
+
+func (r DeletionReport) Failures() []DeletionResult {
+	var failures []DeletionResult
+	for _, result := range r.Results {
+		if result.Err != nil && !result.Skipped {
+			failures = append(failures, result)
+		}
+	}
+	return failures
+}
+
+func (r DeletionReport) Skipped() []DeletionResult {
+	var skipped []DeletionResult
+	for _, result := range r.Results {
+		if result.Skipped {
+			skipped = append(skipped, result)
+		}
+	}
+	return skipped
+}
+
+// Gone returns the caches by id that were already deleted or evicted.
+func (r DeletionReport) Gone() []types.Cache {
+	var gone []types.Cache
+	for _, result := range r.Results {
+		if result.Gone {
+			gone = append(gone, result.Job.Cache)
+		}
+	}
+	return gone
+}
+
+func (r DeletionReport) BytesReclaimed() int64 {
+	var total int64
+	for _, cache := range r.Deleted() {
+		total += cache.SizeInBytes
+	}
+	return total
+}
+
+// DeletionProgress is called after every finished job with the number of finished and total jobs.
+type DeletionProgress func(done int, total int)
+
+// Deleter runs deletion jobs with at most Parallel concurrent requests.
+type Deleter struct {
+	ArtifactCache ArtifactCacheService
+	Parallel      int
+	Progress      DeletionProgress
+	// Finished is called with the result of every job that was started, one call at a time.
+	Finished func(result DeletionResult)
+	// IgnoreNotFound counts a 404 on a cache by id as a success since it was already deleted or evicted.
+	IgnoreNotFound bool
+}
+
+func DeletionJobsById(caches []types.Cache) []DeletionJob {
+	jobs := make([]DeletionJob, 0, len(caches))
+	for _, cache := range caches {
+		jobs = append(jobs, DeletionJob{Cache: cache})
+	}
+	return jobs
+}
+
+// Run executes all jobs. A failed job is recorded in the report and does not stop the remaining ones. Once ctx is
+// asked to stop, the jobs in flight are completed and the remaining ones are recorded as skipped.
+func (d Deleter) Run(ctx context.Context, jobs []DeletionJob) DeletionReport {
+	parallel := d.Parallel
+	if parallel < 1 {
+		parallel = 1
+	}
+
+	results := make([]DeletionResult, len(jobs))
+	indexes := make(chan int)
+	var mutex sync.Mutex
+	var wg sync.WaitGroup
+	done := 0
+
+	for worker := 0; worker < parallel && worker < len(jobs); worker++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				if err := CheckStopped(ctx); err != nil {
+					results[i] = DeletionResult{Job: jobs[i], Err: err, Skipped: true}
+				} else {
+					results[i] = d.runJob(ctx, jobs[i])
+				}
+				mutex.Lock()
+				if d.Finished != nil && !results[i].Skipped {
+					d.Finished(results[i])
+				}
+				if d.Progress != nil {
+					done++
+					d.Progress(done, len(jobs))
+				}
+				mutex.Unlock()
+			}
+		}()
+	}
+
+	for i := range jobs {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	return DeletionReport{Results: results}
+}
+
+func (d Deleter) runJob(ctx context.Context, job DeletionJob) DeletionResult {
+	result := DeletionResult{Job: job}
+	if job.IsKey() {
+		response, err := d.ArtifactCache.DeleteCaches(ctx, job.Query)
+		result.Deleted, result.Err = response.Caches, err
+		return result
+	}
+
+	result.Err = d.ArtifactCache.DeleteCacheById(ctx, job.Cache.Id)
+	var httpError api.HTTPError
+	if d.IgnoreNotFound && errors.As(result.Err, &httpError) && httpError.StatusCode == http.StatusNotFound {
+		result.Err, result.Gone = nil, true
+		return result
+	}
+	if result.Err == nil {
+		result.Deleted = []types.Cache{job.Cache}
+	}
+	return result
+}
@@ -0,0 +1,145 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/actions/gh-actions-cache/internal"
+	"github.com/actions/gh-actions-cache/types"
+	"github.com/cli/go-gh/pkg/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/h2non/gock.v1"
+)
+
+func TestDeleterRun_CollectsFailuresWithoutAborting(t *testing.T) {
+	t.Cleanup(gock.Off)
+
+	gock.New("https://api.github.com").
+		Delete("/repos/testOrg/testRepo/actions/caches/1").
+		Reply(204)
+	gock.New("https://api.github.com").
+		Delete("/repos/testOrg/testRepo/actions/caches/2").
+		Reply(404).
+		JSON(`{"message": "Not Found"}`)
+	gock.New("https://api.github.com").
+		Delete("/repos/testOrg/testRepo/actions/caches/3").
+		Reply(204)
+	gock.New("https://api.github.com").
+		Delete("/repos/testOrg/testRepo/actions/caches").
+		MatchParam("key", "keyFour").
+		Reply(200).
+		JSON(`{"total_count": 1, "actions_caches": [{"id": 4, "key": "keyFour", "size_in_bytes": 400}]}`)
+
+	repo, err := internal.GetRepo("testOrg/testRepo")
+	require.NoError(t, err)
+	artifactCache, err := NewArtifactCache(repo, "delete", VERSION)
+	require.NoError(t, err)
+
+	jobs := DeletionJobsById([]types.Cache{
+		{Id: 1, Key: "keyOne", SizeInBytes: 100},
+		{Id: 2, Key: "keyTwo", SizeInBytes: 200},
+		{Id: 3, Key: "keyThree", SizeInBytes: 300},
+	})
+	jobs = append(jobs, DeletionJob{Query: url.Values{"key": []string{"keyFour"}}})
+
+	var progress []int
+	deleter := Deleter{ArtifactCache: artifactCache, Parallel: 3, Progress: func(done int, total int) {
+		assert.Equal(t, 4, total)
+		progress = append(progress, done)
+	}}
+	report := deleter.Run(context.Background(), jobs)
+
+	require.Len(t, report.Results, 4)
+	assert.Equal(t, []int{1, 2, 3, 4}, progress)
+	assert.Equal(t, []int64{1, 3, 4}, cacheIds(report.Deleted()))
+	assert.Equal(t, int64(800), report.BytesReclaimed())
+
+	failures := report.Failures()
+	if assert.Len(t, failures, 1) {
+		assert.Equal(t, int64(2), failures[0].Job.Cache.Id)
+		var httpError api.HTTPError
+		if assert.ErrorAs(t, failures[0].Err, &httpError) {
+			assert.Equal(t, 404, httpError.StatusCode)
+		}
+	}
+	assert.True(t, gock.IsDone(), internal.PrintPendingMocks(gock.Pending()))
+}
+
+func TestDeleterRun_NoJobs(t *testing.T) {
+	report := Deleter{Parallel: 4}.Run(context.Background(), nil)
+
+	assert.Empty(t, report.Results)
+	assert.Equal(t, int64(0), report.BytesReclaimed())
+}
+
+func cacheIds(caches []types.Cache) []int64 {
+	ids := make([]int64, 0, len(caches))
+	for _, cache := range caches {
+		ids = append(ids, cache.Id)
+	}
+	return ids
+}
+
+func TestDeleterRun_SkipsRemainingJobsWhenStopped(t *testing.T) {
+	t.Cleanup(gock.Off)
+
+	stop := make(chan struct{})
+	gock.New("https://api.github.com").
+		Delete("/repos/testOrg/testRepo/actions/caches/1").
+		Reply(204).
+		Map(func(resp *http.Response) *http.Response {
+			close(stop)
+			return resp
+		})
+
+	repo, err := internal.GetRepo("testOrg/testRepo")
+	require.NoError(t, err)
+	artifactCache, err := NewArtifactCache(repo, "delete", VERSION)
+	require.NoError(t, err)
+
+	ctx := WithStop(context.Background(), stop)
+	report := Deleter{ArtifactCache: artifactCache, Parallel: 1}.Run(ctx, DeletionJobsById([]types.Cache{{Id: 1}, {Id: 2}, {Id: 3}}))
+
+	assert.Equal(t, []int64{1}, cacheIds(report.Deleted()))
+	assert.Empty(t, report.Failures())
+	if assert.Len(t, report.Skipped(), 2) {
+		assert.ErrorIs(t, report.Skipped()[0].Err, types.ErrInterrupted)
+	}
+	assert.True(t, gock.IsDone(), internal.PrintPendingMocks(gock.Pending()))
+}
+
+func TestDeleterRun_IgnoreNotFoundCountsEvictedCachesAsFinished(t *testing.T) {
+	t.Cleanup(gock.Off)
+
+	gock.New("https://api.github.com").
+		Delete("/repos/testOrg/testRepo/actions/caches/1").
+		Reply(204)
+	gock.New("https://api.github.com").
+		Delete("/repos/testOrg/testRepo/actions/caches/2").
+		Reply(404).
+		JSON(`{"message": "Not Found"}`)
+	gock.New("https://api.github.com").
+		Delete("/repos/testOrg/testRepo/actions/caches/3").
+		Reply(403).
+		JSON(`{"message": "Forbidden"}`)
+
+	repo, err := internal.GetRepo("testOrg/testRepo")
+	require.NoError(t, err)
+	artifactCache, err := NewArtifactCache(repo, "prune", VERSION)
+	require.NoError(t, err)
+
+	finished := map[int64]bool{}
+	deleter := Deleter{ArtifactCache: artifactCache, Parallel: 2, IgnoreNotFound: true, Finished: func(result DeletionResult) {
+		finished[result.Job.Cache.Id] = result.Err == nil
+	}}
+	report := deleter.Run(context.Background(), DeletionJobsById([]types.Cache{{Id: 1}, {Id: 2}, {Id: 3}}))
+
+	assert.Equal(t, []int64{1}, cacheIds(report.Deleted()))
+	assert.Equal(t, []int64{2}, cacheIds(report.Gone()))
+	assert.Len(t, report.Failures(), 1)
+	assert.Equal(t, map[int64]bool{1: true, 2: true, 3: false}, finished)
+	assert.True(t, gock.IsDone(), internal.PrintPendingMocks(gock.Pending()))
+}
@@ -0,0 +1,227 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+
+	"github.com/actions/gh-actions-cache/types"
+)
+
+const DEFAULT_PER_PAGE = 30
+const DEFAULT_PAGE_CONCURRENCY = 4
+
+var linkRE = regexp.MustCompile(`<([^>]+)>;\s*rel="([^"]+)"`)
+
+// CacheIterator yields the entries of a cache listing as its pages arrive, in the style of bufio.Scanner:
+//
+//	it := artifactCache.IterateCaches(ctx, queryParams, 1)
+//	defer it.Close()
+//	for it.Next() {
+//		cache := it.Cache()
+//	}
+//	err := it.Err()
+type CacheIterator struct {
+	cancel     context.CancelFunc
+	pages      <-chan cachePage
+	page       []types.Cache
+	current    types.Cache
+	totalCount int
+	err        error
+}
+
+type cachePage struct {
+	caches     []types.Cache
+	totalCount int
+	err        error
+}
+
+// IterateCaches lists the caches matching queryParams. Pages are followed through their Link header so that
+// entries evicted or created between two pages don't end the iteration early. With a concurrency above 1 the pages
+// known from the total count of the first page are fetched that many at a time, and delivered in order.
+func (a *ArtifactCache) IterateCaches(ctx context.Context, queryParams url.Values, concurrency int) *CacheIterator {
+	ctx, cancel := context.WithCancel(ctx)
+	pages := make(chan cachePage, 1)
+	it := &CacheIterator{cancel: cancel, pages: pages}
+	go a.fetchPages(ctx, queryParams, concurrency, pages)
+	return it
+}
+
+// Next advances to the next entry and reports whether there is one. It returns false at the end of the listing
+// or on the first error, which is then available through Err.
+func (it *CacheIterator) Next() bool {
+	for len(it.page) == 0 {
+		if it.err != nil {
+			return false
+		}
+		page, ok := <-it.pages
+		if !ok {
+			return false
+		}
+		if page.err != nil {
+			it.err = page.err
+			it.cancel()
+			return false
+		}
+		it.page = page.caches
+		it.totalCount = page.totalCount
+	}
+	it.current, it.page = it.page[0], it.page[1:]
+	return true
+}
+
+func (it *CacheIterator) Cache() types.Cache {
+	return it.current
+}
+
+// TotalCount returns the total count reported by the most recent page, which can change during the iteration.
+func (it *CacheIterator) TotalCount() int {
+	return it.totalCount
+}
+
+func (it *CacheIterator) Err() error {
+	return it.err
+}
+
+// Close stops fetching pages. It must be called when the iteration is abandoned before Next returns false.
+func (it *CacheIterator) Close() {
+	it.cancel()
+}
+
+func (a *ArtifactCache) fetchPages(ctx context.Context, queryParams url.Values, concurrency int, pages chan<- cachePage) {
+	defer close(pages)
+	send := func(page cachePage) bool {
+		select {
+		case pages <- page:
+			return page.err == nil
+		case <-ctx.Done():
+			return false
+		}
+	}
+
+	path := a.cachesPath(queryParams)
+	first, next, err := a.fetchPage(ctx, path)
+	if !send(cachePage{caches: first.Caches, totalCount: first.TotalCount, err: err}) {
+		return
+	}
+
+	perPage := DEFAULT_PER_PAGE
+	if value, err := strconv.Atoi(queryParams.Get("per_page")); err == nil && value > 0 {
+		perPage = value
+	}
+	if concurrency > 1 && first.TotalCount > perPage && queryParams.Get("page") == "" {
+		lastPage := (first.TotalCount + perPage - 1) / perPage
+		next = a.fetchNumberedPages(ctx, queryParams, 2, lastPage, concurrency, send)
+	}
+
+	for next != "" {
+		if err := CheckStopped(ctx); err != nil {
+			send(cachePage{err: err})
+			return
+		}
+		var response types.CacheList
+		response, next, err = a.fetchPage(ctx, next)
+		if !send(cachePage{caches: response.Caches, totalCount: response.TotalCount, err: err}) {
+			return
+		}
+	}
+}
+
+// fetchNumberedPages fetches the pages from first to last with at most concurrency requests at a time and sends
+// them in order. It returns the next link of the last page, which is set when caches were added meanwhile.
+func (a *ArtifactCache) fetchNumberedPages(ctx context.Context, queryParams url.Values, first int, last int, concurrency int, send func(cachePage) bool) string {
+	type numberedPage struct {
+		page cachePage
+		next string
+	}
+	results := make([]chan numberedPage, 0, last-first+1)
+	for page := first; page <= last; page++ {
+		results = append(results, make(chan numberedPage, 1))
+	}
+
+	semaphore := make(chan struct{}, concurrency)
+	go func() {
+		for i := range results {
+			select {
+			case semaphore <- struct{}{}:
+			case <-ctx.Done():
+				results[i] <- numberedPage{page: cachePage{err: ctx.Err()}}
+				continue
+			}
+			go func(i int) {
+				defer func() { <-semaphore }()
+				if err := CheckStopped(ctx); err != nil {
+					results[i] <- numberedPage{page: cachePage{err: err}}
+					return
+				}
+				pageParams := url.Values{}
+				for name, values := range queryParams {
+					pageParams[name] = values
+				}
+				pageParams.Set("page", strconv.Itoa(first+i))
+				response, next, err := a.fetchPage(ctx, a.cachesPath(pageParams))
+				results[i] <- numberedPage{page: cachePage{caches: response.Caches, totalCount: response.TotalCount, err: err}, next: next}
+			}(i)
+		}
+	}()
+
+	next := ""
+	for _, result := range results {
+		numbered := <-result
+		if !send(numbered.page) {
+			return ""
+		}
+		next = numbered.next
+	}
+	return next
+}
+
+func (a *ArtifactCache) cachesPath(queryParams url.Values) string {
+	return fmt.Sprintf("repos/%s/%s/actions/caches?%s", a.repo.Owner(), a.repo.Name(), queryParams.Encode())
+}
+
+// fetchPage fetches one page of the listing and returns it along with the URL of the next page, if any.
+func (a *ArtifactCache) fetchPage(ctx context.Context, path string) (types.CacheList, string, error) {
+	resp, err := a.HttpClient.RequestWithContext(ctx, http.MethodGet, path, nil)
+	if err != nil {
+		return types.CacheList{}, "", err
+	}
+	defer resp.Body.Close()
+
+	var response types.ListApiResponse
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return types.CacheList{}, "", err
+	}
+	page, err := newCacheList(response.TotalCount, response.ActionsCaches)
+	if err != nil {
+		return types.CacheList{}, "", err
+	}
+	return page, nextPageLink(resp.Header.Get("Link")), nil
+}
+
+func nextPageLink(link string) string {
+	for _, match := range linkRE.FindAllStringSubmatch(link, -1) {
+		if match[2] == "next" {
+			return match[1]
+		}
+	}
+	return ""
+}
+
+// NewCacheIteratorFromPages returns an iterator over pages that were already fetched, e.g. by a fake service,
+// that fails with err after the last page when it is not nil.
+func NewCacheIteratorFromPages(pages []types.CacheList, err error) *CacheIterator {
+	ch := make(chan cachePage, len(pages)+1)
+	for _, page := range pages {
+		ch <- cachePage{caches: page.Caches, totalCount: page.TotalCount}
+	}
+	if err != nil {
+		ch <- cachePage{err: err}
+	}
+	close(ch)
+	return &CacheIterator{cancel: func() {}, pages: ch}
+}
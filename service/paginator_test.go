@@ -0,0 +1,176 @@
+package service
+
+import (
+	"context"
+	"net/url"
+	"testing"
+
+	"github.com/actions/gh-actions-cache/internal"
+	"github.com/actions/gh-actions-cache/types"
+	"github.com/cli/go-gh/pkg/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/h2non/gock.v1"
+)
+
+func newTestArtifactCache(t *testing.T) *ArtifactCache {
+	repo, err := internal.GetRepo("testOrg/testRepo")
+	require.NoError(t, err)
+	artifactCache, err := NewArtifactCache(repo, "list", VERSION)
+	require.NoError(t, err)
+	return artifactCache.(*ArtifactCache)
+}
+
+func collectIds(t *testing.T, it *CacheIterator) []int64 {
+	defer it.Close()
+	var ids []int64
+	for it.Next() {
+		ids = append(ids, it.Cache().Id)
+	}
+	return ids
+}
+
+func TestIterateCaches_FollowsLinkHeaders(t *testing.T) {
+	t.Cleanup(gock.Off)
+
+	gock.New("https://api.github.com").
+		Get("/repos/testOrg/testRepo/actions/caches").
+		MatchParam("per_page", "2").
+		Reply(200).
+		SetHeader("Link", `<https://api.github.com/repositories/1/actions/caches?per_page=2&page=2>; rel="next", <https://api.github.com/repositories/1/actions/caches?per_page=2&page=3>; rel="last"`).
+		JSON(`{"total_count": 5, "actions_caches": [{"id": 1}, {"id": 2}]}`)
+	gock.New("https://api.github.com").
+		Get("/repositories/1/actions/caches").
+		MatchParam("page", "2").
+		Reply(200).
+		SetHeader("Link", `<https://api.github.com/repositories/1/actions/caches?per_page=2&page=3>; rel="next"`).
+		JSON(`{"total_count": 4, "actions_caches": [{"id": 4}, {"id": 5}]}`)
+	gock.New("https://api.github.com").
+		Get("/repositories/1/actions/caches").
+		MatchParam("page", "3").
+		Reply(200).
+		JSON(`{"total_count": 4, "actions_caches": []}`)
+
+	it := newTestArtifactCache(t).IterateCaches(context.Background(), url.Values{"per_page": []string{"2"}}, 1)
+	ids := collectIds(t, it)
+
+	assert.NoError(t, it.Err())
+	assert.Equal(t, []int64{1, 2, 4, 5}, ids)
+	assert.Equal(t, 4, it.TotalCount())
+	assert.True(t, gock.IsDone(), internal.PrintPendingMocks(gock.Pending()))
+}
+
+func TestIterateCaches_FetchesKnownPagesConcurrently(t *testing.T) {
+	t.Cleanup(gock.Off)
+
+	gock.New("https://api.github.com").
+		Get("/repos/testOrg/testRepo/actions/caches").
+		MatchParam("per_page", "2").
+		MatchParam("page", "3").
+		Reply(200).
+		JSON(`{"total_count": 5, "actions_caches": [{"id": 5}]}`)
+	gock.New("https://api.github.com").
+		Get("/repos/testOrg/testRepo/actions/caches").
+		MatchParam("per_page", "2").
+		MatchParam("page", "2").
+		Reply(200).
+		JSON(`{"total_count": 5, "actions_caches": [{"id": 3}, {"id": 4}]}`)
+	gock.New("https://api.github.com").
+		Get("/repos/testOrg/testRepo/actions/caches").
+		MatchParam("per_page", "2").
+		Reply(200).
+		JSON(`{"total_count": 5, "actions_caches": [{"id": 1}, {"id": 2}]}`)
+
+	it := newTestArtifactCache(t).IterateCaches(context.Background(), url.Values{"per_page": []string{"2"}}, 3)
+	ids := collectIds(t, it)
+
+	assert.NoError(t, it.Err())
+	assert.Equal(t, []int64{1, 2, 3, 4, 5}, ids)
+	assert.True(t, gock.IsDone(), internal.PrintPendingMocks(gock.Pending()))
+}
+
+func TestIterateCaches_StopsOnPageError(t *testing.T) {
+	t.Cleanup(gock.Off)
+
+	gock.New("https://api.github.com").
+		Get("/repos/testOrg/testRepo/actions/caches").
+		Reply(200).
+		SetHeader("Link", `<https://api.github.com/repositories/1/actions/caches?page=2>; rel="next"`).
+		JSON(`{"total_count": 31, "actions_caches": [{"id": 1}]}`)
+	gock.New("https://api.github.com").
+		Get("/repositories/1/actions/caches").
+		Reply(404).
+		JSON(`{"message": "Not Found"}`)
+
+	it := newTestArtifactCache(t).IterateCaches(context.Background(), url.Values{}, 1)
+	ids := collectIds(t, it)
+
+	assert.Equal(t, []int64{1}, ids)
+	var httpError api.HTTPError
+	if assert.ErrorAs(t, it.Err(), &httpError) {
+		assert.Equal(t, 404, httpError.StatusCode)
+	}
+	assert.True(t, gock.IsDone(), internal.PrintPendingMocks(gock.Pending()))
+}
+
+func TestListAllCaches_FiltersByKey(t *testing.T) {
+	t.Cleanup(gock.Off)
+
+	gock.New("https://api.github.com").
+		Get("/repos/testOrg/testRepo/actions/caches").
+		MatchParam("key", "Linux-node-").
+		Reply(200).
+		JSON(`{"total_count": 1, "actions_caches": [{"id": 1, "key": "Linux-node-1"}]}`)
+
+	caches, err := newTestArtifactCache(t).ListAllCaches(context.Background(), url.Values{}, "Linux-node-")
+
+	assert.NoError(t, err)
+	assert.Equal(t, []types.Cache{{Id: 1, Key: "Linux-node-1"}}, caches)
+	assert.True(t, gock.IsDone(), internal.PrintPendingMocks(gock.Pending()))
+}
+
+func TestListCacheInventory_DeduplicatesAndReportsDrift(t *testing.T) {
+	t.Cleanup(gock.Off)
+
+	gock.New("https://api.github.com").
+		Get("/repos/testOrg/testRepo/actions/caches").
+		Reply(200).
+		SetHeader("Link", `<https://api.github.com/repositories/1/actions/caches?page=2>; rel="next"`).
+		JSON(`{"total_count": 4, "actions_caches": [{"id": 1}, {"id": 2}]}`)
+	gock.New("https://api.github.com").
+		Get("/repositories/1/actions/caches").
+		Reply(200).
+		JSON(`{"total_count": 4, "actions_caches": [{"id": 2}, {"id": 3}]}`)
+
+	inventory, err := newTestArtifactCache(t).ListCacheInventory(context.Background(), url.Values{}, false)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []types.Cache{{Id: 1}, {Id: 2}, {Id: 3}}, inventory.Caches)
+	assert.Equal(t, 1, inventory.Duplicates)
+	assert.Equal(t, 1, inventory.Drift())
+	assert.False(t, inventory.IsConsistent())
+	assert.True(t, gock.IsDone(), internal.PrintPendingMocks(gock.Pending()))
+}
+
+func TestListCacheInventory_RescansWithStableSort(t *testing.T) {
+	t.Cleanup(gock.Off)
+
+	gock.New("https://api.github.com").
+		Get("/repos/testOrg/testRepo/actions/caches").
+		MatchParam("sort", "created_at").
+		MatchParam("direction", "asc").
+		Reply(200).
+		JSON(`{"total_count": 3, "actions_caches": [{"id": 1}, {"id": 2}, {"id": 3}]}`)
+	gock.New("https://api.github.com").
+		Get("/repos/testOrg/testRepo/actions/caches").
+		Reply(200).
+		JSON(`{"total_count": 3, "actions_caches": [{"id": 1}, {"id": 3}]}`)
+
+	inventory, err := newTestArtifactCache(t).ListCacheInventory(context.Background(), url.Values{}, true)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []types.Cache{{Id: 1}, {Id: 2}, {Id: 3}}, inventory.Caches)
+	assert.True(t, inventory.IsConsistent())
+	assert.Equal(t, 2, inventory.Scans)
+	assert.True(t, gock.IsDone(), internal.PrintPendingMocks(gock.Pending()))
+}
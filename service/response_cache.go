@@ -0,0 +1,218 @@
+package service
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const RESPONSE_CACHE_DIR_NAME = "gh-actions-cache"
+
+// RESPONSE_CACHE_PATH_SUFFIXES are the endpoints whose responses are stored: the cache listing and the cache usage.
+var RESPONSE_CACHE_PATH_SUFFIXES = []string{"/actions/caches", "/actions/cache/usage"}
+
+// ResponseCache configures the response cache of every client created by NewArtifactCache. The root command binds
+// its persistent flags to it.
+var ResponseCache = ResponseCacheOptions{}
+
+type ResponseCacheOptions struct {
+	// TTL is how long a stored response is revalidated before being dropped. The cache is disabled when it is zero.
+	TTL time.Duration
+	// Disabled bypasses the cache whatever the TTL.
+	Disabled bool
+	// Dir defaults to gh-actions-cache in the user cache directory.
+	Dir string
+}
+
+func (o ResponseCacheOptions) Enabled() bool {
+	return o.TTL > 0 && !o.Disabled
+}
+
+// DefaultResponseCacheDir is gh-actions-cache in the user cache directory, e.g. ~/.cache on Linux.
+func DefaultResponseCacheDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, RESPONSE_CACHE_DIR_NAME), nil
+}
+
+// ETagTransport stores the successful GET responses of the cache listing and usage endpoints carrying an ETag or a
+// Last-Modified header on disk and sends them back as If-None-Match and If-Modified-Since. A 304 Not Modified, which
+// does not count against the primary rate limit, is answered with the stored response. Every request is still
+// sent, so a stored response is never served stale. Other requests are passed through.
+type ETagTransport struct {
+	// Base defaults to http.DefaultTransport, looked up on every request.
+	Base http.RoundTripper
+	Dir  string
+	TTL  time.Duration
+	Now  func() time.Time
+}
+
+type storedResponse struct {
+	URL          string      `json:"url"`
+	StatusCode   int         `json:"status_code"`
+	Header       http.Header `json:"header"`
+	Body         []byte      `json:"body"`
+	StoredAt     time.Time   `json:"stored_at"`
+	ETag         string      `json:"etag,omitempty"`
+	LastModified string      `json:"last_modified,omitempty"`
+}
+
+func (t *ETagTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	if req.Method != http.MethodGet || !isStoredEndpoint(req.URL.Path) {
+		return base.RoundTrip(req)
+	}
+
+	path := t.entryPath(req)
+	stored := t.load(path)
+	if stored != nil {
+		req = req.Clone(req.Context())
+		if stored.ETag != "" {
+			req.Header.Set("If-None-Match", stored.ETag)
+		}
+		if stored.LastModified != "" {
+			req.Header.Set("If-Modified-Since", stored.LastModified)
+		}
+	}
+
+	resp, err := base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusNotModified && stored != nil {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+		return stored.response(req, resp.Header), nil
+	}
+	if resp.StatusCode != http.StatusOK || (resp.Header.Get("ETag") == "" && resp.Header.Get("Last-Modified") == "") {
+		return resp, nil
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	// A response that cannot be stored is still returned, the next request is simply not conditional.
+	_ = t.store(path, storedResponse{
+		URL:          req.URL.String(),
+		StatusCode:   resp.StatusCode,
+		Header:       resp.Header,
+		Body:         body,
+		StoredAt:     t.now(),
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+	})
+	return resp, nil
+}
+
+// entryPath names the entry after the URL and the credentials of the request so that responses are never shared
+// across tokens.
+func (t *ETagTransport) entryPath(req *http.Request) string {
+	hash := sha256.New()
+	io.WriteString(hash, req.URL.String())
+	io.WriteString(hash, "\n")
+	io.WriteString(hash, req.Header.Get("Authorization"))
+	io.WriteString(hash, "\n")
+	io.WriteString(hash, req.Header.Get("Accept"))
+	return filepath.Join(t.Dir, hex.EncodeToString(hash.Sum(nil))+".json")
+}
+
+// load returns the entry at path, or nil when there is none or it is older than the TTL.
+func (t *ETagTransport) load(path string) *storedResponse {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	var stored storedResponse
+	if err := json.Unmarshal(data, &stored); err != nil || t.now().Sub(stored.StoredAt) > t.TTL {
+		_ = os.Remove(path)
+		return nil
+	}
+	return &stored
+}
+
+// store writes the entry to a temporary file renamed over path so that concurrent commands never read half an
+// entry.
+func (t *ETagTransport) store(path string, stored storedResponse) error {
+	data, err := json.Marshal(stored)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(t.Dir, 0o700); err != nil {
+		return err
+	}
+	file, err := os.CreateTemp(t.Dir, ".entry-*")
+	if err != nil {
+		return err
+	}
+	_, err = file.Write(data)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(file.Name(), path)
+	}
+	if err != nil {
+		_ = os.Remove(file.Name())
+	}
+	return err
+}
+
+func (t *ETagTransport) now() time.Time {
+	if t.Now == nil {
+		return time.Now()
+	}
+	return t.Now()
+}
+
+// response rebuilds the stored response, with the headers of the 304 such as the rate limit ones taking
+// precedence.
+func (s *storedResponse) response(req *http.Request, fresh http.Header) *http.Response {
+	header := s.Header.Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+	for name, values := range fresh {
+		if strings.EqualFold(name, "Content-Length") {
+			continue
+		}
+		header[name] = values
+	}
+	header.Set("Content-Length", strconv.Itoa(len(s.Body)))
+	return &http.Response{
+		Status:        strconv.Itoa(s.StatusCode) + " " + http.StatusText(s.StatusCode),
+		StatusCode:    s.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(s.Body)),
+		ContentLength: int64(len(s.Body)),
+		Request:       req,
+	}
+}
+
+func isStoredEndpoint(path string) bool {
+	for _, suffix := range RESPONSE_CACHE_PATH_SUFFIXES {
+		if strings.HasSuffix(path, suffix) {
+			return true
+		}
+	}
+	return false
+}
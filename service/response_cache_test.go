@@ -0,0 +1,146 @@
+package service
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/actions/gh-actions-cache/internal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/h2non/gock.v1"
+)
+
+func newTestETagTransport(t *testing.T, base http.RoundTripper, now *time.Time) *ETagTransport {
+	return &ETagTransport{Base: base, Dir: t.TempDir(), TTL: time.Hour, Now: func() time.Time { return *now }}
+}
+
+func getWithToken(t *testing.T, transport http.RoundTripper, token string) (*http.Response, string) {
+	req, err := http.NewRequest("GET", "https://api.github.com/repos/testOrg/testRepo/actions/caches?per_page=100", nil)
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "token "+token)
+	resp, err := transport.RoundTrip(req)
+	require.NoError(t, err)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return resp, string(body)
+}
+
+func TestETagTransport_AnswersNotModifiedWithTheStoredResponse(t *testing.T) {
+	var conditions []string
+	responses := []*http.Response{
+		newResponse(200, map[string]string{"ETag": `"abc"`, "X-RateLimit-Remaining": "59"}),
+		newResponse(304, map[string]string{"ETag": `"abc"`, "X-RateLimit-Remaining": "58"}),
+	}
+	responses[0].Body = io.NopCloser(strings.NewReader(`{"total_count": 1}`))
+	base := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		conditions = append(conditions, req.Header.Get("If-None-Match"))
+		resp := responses[0]
+		responses = responses[1:]
+		return resp, nil
+	})
+	now := time.Unix(1000, 0)
+	transport := newTestETagTransport(t, base, &now)
+
+	_, first := getWithToken(t, transport, "ghp_a")
+	now = now.Add(time.Minute)
+	resp, second := getWithToken(t, transport, "ghp_a")
+
+	assert.Equal(t, []string{"", `"abc"`}, conditions)
+	assert.Equal(t, `{"total_count": 1}`, first)
+	assert.Equal(t, first, second)
+	assert.Equal(t, 200, resp.StatusCode)
+	assert.Equal(t, "58", resp.Header.Get("X-RateLimit-Remaining"))
+}
+
+func TestETagTransport_DropsExpiredEntriesAndSeparatesTokens(t *testing.T) {
+	var conditions []string
+	base := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		conditions = append(conditions, req.Header.Get("If-None-Match"))
+		return newResponse(200, map[string]string{"ETag": `"abc"`}), nil
+	})
+	now := time.Unix(1000, 0)
+	transport := newTestETagTransport(t, base, &now)
+
+	getWithToken(t, transport, "ghp_a")
+	getWithToken(t, transport, "ghp_b")
+	now = now.Add(2 * time.Hour)
+	getWithToken(t, transport, "ghp_a")
+
+	assert.Equal(t, []string{"", "", ""}, conditions)
+}
+
+func TestETagTransport_PassesOtherMethodsThrough(t *testing.T) {
+	base, calls := scriptedTransport(newResponse(204, map[string]string{"ETag": `"abc"`}), newResponse(204, nil))
+	now := time.Unix(1000, 0)
+	transport := newTestETagTransport(t, base, &now)
+
+	for i := 0; i < 2; i++ {
+		req, err := http.NewRequest("DELETE", "https://api.github.com/repos/testOrg/testRepo/actions/caches/1", nil)
+		require.NoError(t, err)
+		resp, err := transport.RoundTrip(req)
+		require.NoError(t, err)
+		assert.Equal(t, 204, resp.StatusCode)
+		assert.Empty(t, req.Header.Get("If-None-Match"))
+	}
+	assert.Equal(t, 2, *calls)
+}
+
+func TestListCaches_RevalidatesStoredListing(t *testing.T) {
+	t.Cleanup(gock.Off)
+
+	gock.New("https://api.github.com").
+		Get("/repos/testOrg/testRepo/actions/caches").
+		Reply(200).
+		SetHeader("ETag", `"v1"`).
+		JSON(`{"total_count": 1, "actions_caches": [{"id": 1, "key": "Linux-node-a", "ref": "refs/heads/main", "last_accessed_at": "2022-06-29T13:33:49Z", "created_at": "2022-06-29T13:33:49Z", "size_in_bytes": 100}]}`)
+	gock.New("https://api.github.com").
+		Get("/repos/testOrg/testRepo/actions/caches").
+		MatchHeader("If-None-Match", `"v1"`).
+		Reply(304)
+
+	repo, err := internal.GetRepo("testOrg/testRepo")
+	require.NoError(t, err)
+	artifactCache, err := NewArtifactCacheWithOptions(repo, ArtifactCacheOptions{
+		AuthToken: "dummy",
+		Cache:     ResponseCacheOptions{TTL: time.Hour, Dir: t.TempDir()},
+	})
+	require.NoError(t, err)
+
+	first, err := artifactCache.ListCaches(context.Background(), url.Values{})
+	require.NoError(t, err)
+	second, err := artifactCache.ListCaches(context.Background(), url.Values{})
+	require.NoError(t, err)
+
+	assert.Equal(t, first, second)
+	assert.Equal(t, "Linux-node-a", second.Caches[0].Key)
+	assert.True(t, gock.IsDone(), internal.PrintPendingMocks(gock.Pending()))
+}
+
+func TestETagTransport_OnlyStoresTheListingAndUsage(t *testing.T) {
+	var conditions []string
+	base := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		conditions = append(conditions, req.Header.Get("If-None-Match"))
+		return newResponse(200, map[string]string{"ETag": `"abc"`}), nil
+	})
+	now := time.Unix(1000, 0)
+	transport := newTestETagTransport(t, base, &now)
+
+	for _, url := range []string{
+		"https://api.github.com/user",
+		"https://api.github.com/user",
+		"https://api.github.com/repos/testOrg/testRepo/actions/cache/usage",
+		"https://api.github.com/repos/testOrg/testRepo/actions/cache/usage",
+	} {
+		req, err := http.NewRequest("GET", url, nil)
+		require.NoError(t, err)
+		_, err = transport.RoundTrip(req)
+		require.NoError(t, err)
+	}
+
+	assert.Equal(t, []string{"", "", "", `"abc"`}, conditions)
+}
@@ -0,0 +1,147 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const DEFAULT_MAX_RETRIES = 5
+const DEFAULT_MAX_RETRY_WAIT = time.Minute
+
+var INITIAL_BACKOFF = time.Second
+
+// HttpOptions configures the retries of every client created by NewArtifactCache. The root command binds its
+// persistent flags to it.
+var HttpOptions = RetryOptions{MaxRetries: DEFAULT_MAX_RETRIES, MaxWait: DEFAULT_MAX_RETRY_WAIT}
+
+type RetryOptions struct {
+	MaxRetries int
+	// MaxWait is the longest total time spent waiting between the attempts of a single request. A request whose next
+	// wait would exceed it fails with the last response instead.
+	MaxWait time.Duration
+	// Verbose logs every request, the remaining rate limit and the retries.
+	Verbose bool
+	// Debug also logs the request headers and the body of failed responses, it implies Verbose.
+	Debug bool
+}
+
+// RetryTransport retries requests failing with a 5xx, a 429 or a rate limit 403, waiting as told by the
+// Retry-After and X-RateLimit-Reset headers or with an exponential backoff with jitter otherwise.
+type RetryTransport struct {
+	Options RetryOptions
+	// Base defaults to http.DefaultTransport, looked up on every request.
+	Base  http.RoundTripper
+	Log   io.Writer
+	Sleep func(context.Context, time.Duration) error
+	Now   func() time.Time
+}
+
+func NewRetryTransport(options RetryOptions) *RetryTransport {
+	return &RetryTransport{Options: options, Log: os.Stderr, Sleep: sleepContext, Now: time.Now}
+}
+
+func (t *RetryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	var waited time.Duration
+	for attempt := 0; ; attempt++ {
+		if attempt > 0 && req.Body != nil && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req.Body = body
+		}
+
+		resp, err := base.RoundTrip(req)
+		if err != nil {
+			return nil, err
+		}
+		t.logRateLimit(resp)
+
+		if !isRetryable(resp) || attempt >= t.Options.MaxRetries || (req.Body != nil && req.GetBody == nil) {
+			return resp, nil
+		}
+		wait := t.retryDelay(resp, attempt)
+		if waited+wait > t.Options.MaxWait {
+			return resp, nil
+		}
+
+		t.logf("Retrying %s %s in %s after %s (attempt %d of %d)\n", req.Method, req.URL.Path, wait, resp.Status, attempt+2, t.Options.MaxRetries+1)
+		_, _ = io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+
+		if err := t.Sleep(req.Context(), wait); err != nil {
+			return nil, err
+		}
+		waited += wait
+	}
+}
+
+// sleepContext waits for d unless ctx is done first.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+func isRetryable(resp *http.Response) bool {
+	switch {
+	case resp.StatusCode >= 500, resp.StatusCode == http.StatusTooManyRequests:
+		return true
+	case resp.StatusCode == http.StatusForbidden:
+		// Secondary rate limits come with Retry-After, primary ones with no requests remaining.
+		return resp.Header.Get("Retry-After") != "" || resp.Header.Get("X-RateLimit-Remaining") == "0"
+	}
+	return false
+}
+
+func (t *RetryTransport) retryDelay(resp *http.Response, attempt int) time.Duration {
+	if seconds, err := strconv.Atoi(strings.TrimSpace(resp.Header.Get("Retry-After"))); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if resp.Header.Get("X-RateLimit-Remaining") == "0" {
+		if reset, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+			if wait := time.Unix(reset, 0).Sub(t.Now()); wait > 0 {
+				return wait
+			}
+			return 0
+		}
+	}
+
+	backoff := INITIAL_BACKOFF << attempt
+	return backoff/2 + time.Duration(rand.Int63n(int64(backoff/2)+1))
+}
+
+func (t *RetryTransport) logRateLimit(resp *http.Response) {
+	remaining := resp.Header.Get("X-RateLimit-Remaining")
+	if remaining == "" {
+		return
+	}
+	message := fmt.Sprintf("Rate limit: %s of %s requests remaining", remaining, resp.Header.Get("X-RateLimit-Limit"))
+	if reset, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+		message += fmt.Sprintf(", resets at %s", time.Unix(reset, 0).Format(time.Kitchen))
+	}
+	t.logf("%s\n", message)
+}
+
+func (t *RetryTransport) logf(format string, a ...interface{}) {
+	if t.Options.Verbose && t.Log != nil {
+		fmt.Fprintf(t.Log, format, a...)
+	}
+}
@@ -0,0 +1,151 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// scriptedTransport replies with the given responses in order and counts the requests.
+func scriptedTransport(responses ...*http.Response) (http.RoundTripper, *int) {
+	calls := 0
+	return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		resp := responses[calls]
+		calls++
+		return resp, nil
+	}), &calls
+}
+
+func newResponse(status int, headers map[string]string) *http.Response {
+	resp := &http.Response{StatusCode: status, Status: http.StatusText(status), Header: http.Header{}, Body: io.NopCloser(strings.NewReader("{}"))}
+	for name, value := range headers {
+		resp.Header.Set(name, value)
+	}
+	return resp
+}
+
+func newTestRetryTransport(base http.RoundTripper, options RetryOptions) (*RetryTransport, *[]time.Duration) {
+	var sleeps []time.Duration
+	transport := NewRetryTransport(options)
+	transport.Base = base
+	transport.Sleep = func(ctx context.Context, d time.Duration) error {
+		sleeps = append(sleeps, d)
+		return nil
+	}
+	transport.Now = func() time.Time { return time.Unix(1000, 0) }
+	return transport, &sleeps
+}
+
+func TestRetryTransport_RetriesServerErrorsWithBackoff(t *testing.T) {
+	base, calls := scriptedTransport(newResponse(502, nil), newResponse(503, nil), newResponse(200, nil))
+	transport, sleeps := newTestRetryTransport(base, RetryOptions{MaxRetries: 5, MaxWait: time.Minute})
+
+	req, err := http.NewRequest("GET", "https://api.github.com/repos/testOrg/testRepo/actions/caches", nil)
+	require.NoError(t, err)
+	resp, err := transport.RoundTrip(req)
+
+	require.NoError(t, err)
+	assert.Equal(t, 200, resp.StatusCode)
+	assert.Equal(t, 3, *calls)
+	require.Len(t, *sleeps, 2)
+	assert.True(t, (*sleeps)[0] >= INITIAL_BACKOFF/2 && (*sleeps)[0] <= INITIAL_BACKOFF)
+	assert.True(t, (*sleeps)[1] >= INITIAL_BACKOFF && (*sleeps)[1] <= 2*INITIAL_BACKOFF)
+}
+
+func TestRetryTransport_HonorsRetryAfterOnSecondaryRateLimit(t *testing.T) {
+	base, calls := scriptedTransport(newResponse(403, map[string]string{"Retry-After": "7"}), newResponse(204, nil))
+	transport, sleeps := newTestRetryTransport(base, RetryOptions{MaxRetries: 5, MaxWait: time.Minute})
+
+	req, err := http.NewRequest("DELETE", "https://api.github.com/repos/testOrg/testRepo/actions/caches/1", nil)
+	require.NoError(t, err)
+	resp, err := transport.RoundTrip(req)
+
+	require.NoError(t, err)
+	assert.Equal(t, 204, resp.StatusCode)
+	assert.Equal(t, 2, *calls)
+	assert.Equal(t, []time.Duration{7 * time.Second}, *sleeps)
+}
+
+func TestRetryTransport_WaitsForRateLimitReset(t *testing.T) {
+	base, _ := scriptedTransport(newResponse(429, map[string]string{"X-RateLimit-Remaining": "0", "X-RateLimit-Reset": "1030"}), newResponse(200, nil))
+	transport, sleeps := newTestRetryTransport(base, RetryOptions{MaxRetries: 5, MaxWait: time.Minute})
+
+	req, err := http.NewRequest("GET", "https://api.github.com/repos/testOrg/testRepo/actions/caches", nil)
+	require.NoError(t, err)
+	_, err = transport.RoundTrip(req)
+
+	require.NoError(t, err)
+	assert.Equal(t, []time.Duration{30 * time.Second}, *sleeps)
+}
+
+func TestRetryTransport_GivesUpWhenWaitExceedsMaxWait(t *testing.T) {
+	base, calls := scriptedTransport(newResponse(429, map[string]string{"Retry-After": "120"}), newResponse(200, nil))
+	transport, sleeps := newTestRetryTransport(base, RetryOptions{MaxRetries: 5, MaxWait: time.Minute})
+
+	req, err := http.NewRequest("GET", "https://api.github.com/repos/testOrg/testRepo/actions/caches", nil)
+	require.NoError(t, err)
+	resp, err := transport.RoundTrip(req)
+
+	require.NoError(t, err)
+	assert.Equal(t, 429, resp.StatusCode)
+	assert.Equal(t, 1, *calls)
+	assert.Empty(t, *sleeps)
+}
+
+func TestRetryTransport_DoesNotRetryClientErrors(t *testing.T) {
+	base, calls := scriptedTransport(newResponse(404, nil), newResponse(200, nil))
+	transport, _ := newTestRetryTransport(base, RetryOptions{MaxRetries: 5, MaxWait: time.Minute})
+
+	req, err := http.NewRequest("GET", "https://api.github.com/repos/testOrg/testRepo/actions/caches", nil)
+	require.NoError(t, err)
+	resp, err := transport.RoundTrip(req)
+
+	require.NoError(t, err)
+	assert.Equal(t, 404, resp.StatusCode)
+	assert.Equal(t, 1, *calls)
+}
+
+func TestRetryTransport_ReportsRateLimitInVerboseMode(t *testing.T) {
+	headers := map[string]string{"X-RateLimit-Remaining": "4990", "X-RateLimit-Limit": "5000", "X-RateLimit-Reset": "1030"}
+	base, _ := scriptedTransport(newResponse(503, headers), newResponse(200, headers))
+	transport, _ := newTestRetryTransport(base, RetryOptions{MaxRetries: 5, MaxWait: time.Minute, Verbose: true})
+	var log bytes.Buffer
+	transport.Log = &log
+
+	req, err := http.NewRequest("GET", "https://api.github.com/repos/testOrg/testRepo/actions/caches", nil)
+	require.NoError(t, err)
+	_, err = transport.RoundTrip(req)
+
+	require.NoError(t, err)
+	assert.Contains(t, log.String(), "Rate limit: 4990 of 5000 requests remaining, resets at ")
+	assert.Contains(t, log.String(), "Retrying GET /repos/testOrg/testRepo/actions/caches in ")
+	assert.Contains(t, log.String(), "(attempt 2 of 6)")
+}
+
+func TestRetryTransport_StopsWaitingWhenContextIsDone(t *testing.T) {
+	base, calls := scriptedTransport(newResponse(503, map[string]string{"Retry-After": "30"}), newResponse(200, nil))
+	transport := NewRetryTransport(RetryOptions{MaxRetries: 5, MaxWait: time.Minute})
+	transport.Base = base
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, "GET", "https://api.github.com/repos/testOrg/testRepo/actions/caches", nil)
+	require.NoError(t, err)
+	_, err = transport.RoundTrip(req)
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, 1, *calls)
+}
@@ -0,0 +1,99 @@
+package service
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// MAX_TRACED_BODY is the number of bytes of a failed response body logged in debug mode.
+const MAX_TRACED_BODY = 4096
+
+var tokenRE = regexp.MustCompile(`\b(gh[pousr]_|github_pat_)[A-Za-z0-9_]+`)
+
+// TraceTransport logs every request with its status and duration. In debug mode it also logs the request headers
+// and the body of failed responses. Tokens are redacted from everything it logs.
+type TraceTransport struct {
+	// Base defaults to http.DefaultTransport, looked up on every request.
+	Base  http.RoundTripper
+	Log   io.Writer
+	Debug bool
+	Now   func() time.Time
+}
+
+func (t *TraceTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	now := t.Now
+	if now == nil {
+		now = time.Now
+	}
+
+	start := now()
+	resp, err := base.RoundTrip(req)
+	elapsed := now().Sub(start).Round(time.Millisecond)
+	if err != nil {
+		t.logf("%s %s failed after %s: %s\n", req.Method, Redact(req.URL.String()), elapsed, Redact(err.Error()))
+		return nil, err
+	}
+
+	t.logf("%s %s %s in %s\n", req.Method, Redact(req.URL.String()), resp.Status, elapsed)
+	if !t.Debug {
+		return resp, nil
+	}
+	t.logHeaders("> ", req.Header)
+	if resp.StatusCode >= 400 {
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		resp.Body = io.NopCloser(bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		if len(body) > MAX_TRACED_BODY {
+			body = append(body[:MAX_TRACED_BODY:MAX_TRACED_BODY], "..."...)
+		}
+		t.logf("< %s\n", Redact(string(body)))
+	}
+	return resp, nil
+}
+
+func (t *TraceTransport) logHeaders(prefix string, header http.Header) {
+	names := make([]string, 0, len(header))
+	for name := range header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		value := strings.Join(header.Values(name), ", ")
+		if strings.EqualFold(name, "Authorization") {
+			value = redactAuthorization(value)
+		}
+		t.logf("%s%s: %s\n", prefix, name, Redact(value))
+	}
+}
+
+func (t *TraceTransport) logf(format string, a ...interface{}) {
+	if t.Log != nil {
+		fmt.Fprintf(t.Log, format, a...)
+	}
+}
+
+// Redact hides GitHub tokens found in s.
+func Redact(s string) string {
+	return tokenRE.ReplaceAllString(s, "${1}***")
+}
+
+// redactAuthorization keeps the scheme of an Authorization header and hides its credentials.
+func redactAuthorization(value string) string {
+	if scheme, _, found := strings.Cut(value, " "); found {
+		return scheme + " ***"
+	}
+	return "***"
+}
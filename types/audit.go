@@ -0,0 +1,28 @@
+package types
+
+import "time"
+
+// AuditRecord is a line of the audit log, written for every deleted cache.
+type AuditRecord struct {
+	Time        time.Time `json:"time"`
+	Host        string    `json:"host"`
+	Repo        string    `json:"repo"`
+	User        string    `json:"user"`
+	CacheId     int64     `json:"cache_id"`
+	Key         string    `json:"key"`
+	Ref         string    `json:"ref"`
+	Version     string    `json:"version"`
+	SizeInBytes int64     `json:"size_in_bytes"`
+	Command     string    `json:"command"`
+	Reason      string    `json:"reason,omitempty"`
+}
+
+// AuditFilter selects audit records. Zero fields match every record.
+type AuditFilter struct {
+	Since time.Time
+	Until time.Time
+	// Repo is OWNER/REPO or HOST/OWNER/REPO.
+	Repo string
+	// Key matches the keys starting with it.
+	Key string
+}
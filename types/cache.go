@@ -0,0 +1,154 @@
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type RefKind int
+
+const (
+	RefOther RefKind = iota
+	RefBranch
+	RefTag
+	RefPullRequest
+)
+
+// Ref is a decoded git ref such as refs/heads/main, refs/tags/v1.0 or refs/pull/2/merge.
+type Ref struct {
+	Raw  string
+	Kind RefKind
+	// Name is the branch or tag name, or the raw ref for other refs.
+	Name string
+	// PullRequest is the number of the pull request and PullRequestRef is merge or head.
+	PullRequest    int
+	PullRequestRef string
+}
+
+func ParseRef(raw string) Ref {
+	ref := Ref{Raw: raw, Kind: RefOther, Name: raw}
+	switch {
+	case strings.HasPrefix(raw, "refs/heads/"):
+		ref.Kind, ref.Name = RefBranch, strings.TrimPrefix(raw, "refs/heads/")
+	case strings.HasPrefix(raw, "refs/tags/"):
+		ref.Kind, ref.Name = RefTag, strings.TrimPrefix(raw, "refs/tags/")
+	case strings.HasPrefix(raw, "refs/pull/"):
+		parts := strings.Split(strings.TrimPrefix(raw, "refs/pull/"), "/")
+		if number, err := strconv.Atoi(parts[0]); err == nil && len(parts) == 2 {
+			ref.Kind, ref.PullRequest, ref.PullRequestRef = RefPullRequest, number, parts[1]
+		}
+	}
+	return ref
+}
+
+func (r Ref) String() string {
+	return r.Raw
+}
+
+// Cache is a cache entry with parsed values. It is converted once from the ActionsCache returned by the API and
+// encodes back to the same JSON.
+type Cache struct {
+	Id             int64
+	Ref            Ref
+	Key            string
+	Version        string
+	LastAccessedAt time.Time
+	CreatedAt      time.Time
+	SizeInBytes    int64
+}
+
+// NewCache converts an API cache entry. Missing timestamps are left zero, invalid ones are an error.
+func NewCache(raw ActionsCache) (Cache, error) {
+	lastAccessedAt, err := parseApiTime(raw.LastAccessedAt)
+	if err != nil {
+		return Cache{}, fmt.Errorf("invalid last_accessed_at of cache %d: %w", raw.Id, err)
+	}
+	createdAt, err := parseApiTime(raw.CreatedAt)
+	if err != nil {
+		return Cache{}, fmt.Errorf("invalid created_at of cache %d: %w", raw.Id, err)
+	}
+	return Cache{
+		Id:             int64(raw.Id),
+		Ref:            ParseRef(raw.Ref),
+		Key:            raw.Key,
+		Version:        raw.Version,
+		LastAccessedAt: lastAccessedAt,
+		CreatedAt:      createdAt,
+		SizeInBytes:    int64(raw.SizeInBytes),
+	}, nil
+}
+
+func NewCaches(raw []ActionsCache) ([]Cache, error) {
+	caches := make([]Cache, 0, len(raw))
+	for _, r := range raw {
+		cache, err := NewCache(r)
+		if err != nil {
+			return nil, err
+		}
+		caches = append(caches, cache)
+	}
+	return caches, nil
+}
+
+// ApiModel converts the cache back to the representation of the API.
+func (c Cache) ApiModel() ActionsCache {
+	return ActionsCache{
+		Id:             int(c.Id),
+		Ref:            c.Ref.Raw,
+		Key:            c.Key,
+		Version:        c.Version,
+		LastAccessedAt: FormatApiTime(c.LastAccessedAt),
+		CreatedAt:      FormatApiTime(c.CreatedAt),
+		SizeInBytes:    float64(c.SizeInBytes),
+	}
+}
+
+func (c Cache) MarshalJSON() ([]byte, error) {
+	return json.Marshal(c.ApiModel())
+}
+
+func (c *Cache) UnmarshalJSON(data []byte) error {
+	var raw ActionsCache
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	cache, err := NewCache(raw)
+	if err != nil {
+		return err
+	}
+	*c = cache
+	return nil
+}
+
+// CacheList is a page of a listing, or the caches deleted by key, along with the total count of the API.
+type CacheList struct {
+	TotalCount int
+	Caches     []Cache
+}
+
+// MarshalJSON encodes the list like the listing responses of the API.
+func (l CacheList) MarshalJSON() ([]byte, error) {
+	response := ListApiResponse{TotalCount: l.TotalCount, ActionsCaches: make([]ActionsCache, 0, len(l.Caches))}
+	for _, cache := range l.Caches {
+		response.ActionsCaches = append(response.ActionsCaches, cache.ApiModel())
+	}
+	return json.Marshal(response)
+}
+
+func parseApiTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(time.RFC3339Nano, value)
+}
+
+// FormatApiTime formats t like the timestamps of the API, or returns an empty string for a zero time.
+func FormatApiTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339Nano)
+}
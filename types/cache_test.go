@@ -0,0 +1,56 @@
+package types
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRef(t *testing.T) {
+	assert.Equal(t, Ref{Raw: "refs/heads/feature/a", Kind: RefBranch, Name: "feature/a"}, ParseRef("refs/heads/feature/a"))
+	assert.Equal(t, Ref{Raw: "refs/tags/v1.0", Kind: RefTag, Name: "v1.0"}, ParseRef("refs/tags/v1.0"))
+	assert.Equal(t, Ref{Raw: "refs/pull/42/merge", Kind: RefPullRequest, Name: "refs/pull/42/merge", PullRequest: 42, PullRequestRef: "merge"}, ParseRef("refs/pull/42/merge"))
+	assert.Equal(t, Ref{Raw: "refs/pull/x/head", Kind: RefOther, Name: "refs/pull/x/head"}, ParseRef("refs/pull/x/head"))
+	assert.Equal(t, Ref{}, ParseRef(""))
+}
+
+func TestNewCache(t *testing.T) {
+	cache, err := NewCache(ActionsCache{
+		Id:             29,
+		Ref:            "refs/pull/2/head",
+		Key:            "Linux-node-a",
+		Version:        "7fcda33c",
+		LastAccessedAt: "2022-06-22T20:32:45.550000000Z",
+		CreatedAt:      "2022-06-21T10:00:00Z",
+		SizeInBytes:    2432967,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, int64(29), cache.Id)
+	assert.Equal(t, RefPullRequest, cache.Ref.Kind)
+	assert.Equal(t, "head", cache.Ref.PullRequestRef)
+	assert.Equal(t, time.Date(2022, 6, 22, 20, 32, 45, 550000000, time.UTC), cache.LastAccessedAt)
+	assert.Equal(t, time.Date(2022, 6, 21, 10, 0, 0, 0, time.UTC), cache.CreatedAt)
+	assert.Equal(t, int64(2432967), cache.SizeInBytes)
+}
+
+func TestNewCache_InvalidTimestamp(t *testing.T) {
+	_, err := NewCache(ActionsCache{Id: 29, LastAccessedAt: "yesterday"})
+
+	assert.ErrorContains(t, err, "invalid last_accessed_at of cache 29")
+}
+
+func TestCache_JSONRoundTrip(t *testing.T) {
+	data := `{"id":29,"ref":"refs/heads/main","key":"Linux-node-a","version":"7fcda33c","last_accessed_at":"2022-06-22T20:32:45.55Z","created_at":"2022-06-21T10:00:00Z","size_in_bytes":2432967}`
+
+	var cache Cache
+	require.NoError(t, json.Unmarshal([]byte(data), &cache))
+	assert.Equal(t, "main", cache.Ref.Name)
+
+	encoded, err := json.Marshal(cache)
+	require.NoError(t, err)
+	assert.JSONEq(t, data, string(encoded))
+}
@@ -0,0 +1,305 @@
+package types
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var TABLE_COLUMNS = []string{"key", "size", "ref", "last-used", "created-at", "id", "version"}
+var DEFAULT_TABLE_COLUMNS = []string{"key", "size", "ref", "last-used"}
+var TIME_FORMATS = []string{"relative", "absolute"}
+
+// Config holds the defaults read from a configuration file or from the environment. Empty fields are not set.
+type Config struct {
+	Repo                  string   `yaml:"repo,omitempty"`
+	Format                string   `yaml:"format,omitempty"`
+	Columns               []string `yaml:"columns,omitempty"`
+	TimeFormat            string   `yaml:"time-format,omitempty"`
+	Sort                  string   `yaml:"sort,omitempty"`
+	Order                 string   `yaml:"order,omitempty"`
+	Limit                 int      `yaml:"limit,omitempty"`
+	CacheTTL              string   `yaml:"cache-ttl,omitempty"`
+	ProtectedRefs         []string `yaml:"protected-refs,omitempty"`
+	ProtectedKeyPrefixes  []string `yaml:"protected-key-prefixes,omitempty"`
+	MaxDeleteEntries      int      `yaml:"max-delete-entries,omitempty"`
+	MaxDeleteSize         string   `yaml:"max-delete-size,omitempty"`
+	MaxDeleteUsagePercent float64  `yaml:"max-delete-usage-percent,omitempty"`
+	AuditLog              string   `yaml:"audit-log,omitempty"`
+}
+
+// ConfigKey is a setting of Config. Lists are read and written as comma separated values.
+type ConfigKey struct {
+	Name        string
+	Description string
+	// Flag is the flag whose default the setting replaces, if any.
+	Flag string
+	// Commands restricts the flag to these commands, all of them when empty.
+	Commands []string
+	// Cumulative settings add up across the configuration layers and are added to the values given to the flag instead
+	// of being replaced by them.
+	Cumulative bool
+	// UserOnly settings are ignored in the repository file, which any checked out repository controls.
+	UserOnly bool
+	get      func(c *Config) string
+	set      func(c *Config, value string) error
+	// limit is the amount of the settings that are limits, which resolve to their lowest value across the
+	// configuration layers so that a repository file can lower the limits of the user but not raise them.
+	limit func(c *Config) float64
+}
+
+var CONFIG_KEYS = []ConfigKey{
+	{
+		Name: "repo", Description: "Repository used without --repo, in the [HOST/]OWNER/REPO format", Flag: "repo",
+		get: func(c *Config) string { return c.Repo },
+		set: func(c *Config, value string) error {
+			if value != "" && !strings.Contains(value, "/") {
+				return fmt.Errorf("%s is not a valid value for repo. Expected [HOST/]OWNER/REPO", value)
+			}
+			c.Repo = value
+			return nil
+		},
+	},
+	{
+		Name: "format", Description: "Output format of list", Flag: "format", Commands: []string{"list"},
+		get: func(c *Config) string { return c.Format },
+		set: func(c *Config, value string) error {
+			if value != "" && !isValidOutputFormat(value) {
+				return fmt.Errorf("%s is not a valid value for format. Allowed values: %s", value, strings.Join(OUTPUT_FORMATS, "/"))
+			}
+			c.Format = value
+			return nil
+		},
+	},
+	{
+		Name: "columns", Description: "Columns of the tables, among " + strings.Join(TABLE_COLUMNS, ","),
+		get: func(c *Config) string { return strings.Join(c.Columns, ",") },
+		set: func(c *Config, value string) error {
+			columns := SplitConfigList(value)
+			for _, column := range columns {
+				if !contains(TABLE_COLUMNS, column) {
+					return fmt.Errorf("%s is not a valid column. Allowed values: %s", column, strings.Join(TABLE_COLUMNS, ","))
+				}
+			}
+			c.Columns = columns
+			return nil
+		},
+	},
+	{
+		Name: "time-format", Description: "How times are shown in the tables (relative/absolute)",
+		get: func(c *Config) string { return c.TimeFormat },
+		set: func(c *Config, value string) error {
+			if value != "" && !contains(TIME_FORMATS, value) {
+				return fmt.Errorf("%s is not a valid value for time-format. Allowed values: %s", value, strings.Join(TIME_FORMATS, "/"))
+			}
+			c.TimeFormat = value
+			return nil
+		},
+	},
+	{
+		Name: "sort", Description: "Sort order of list (last-used/size/created-at)", Flag: "sort", Commands: []string{"list"},
+		get: func(c *Config) string { return c.Sort },
+		set: func(c *Config, value string) error {
+			if _, ok := SORT_INPUT_TO_QUERY_MAP[value]; value != "" && !ok {
+				return fmt.Errorf("%s is not a valid value for sort. Allowed values: last-used/size/created-at", value)
+			}
+			c.Sort = value
+			return nil
+		},
+	},
+	{
+		Name: "order", Description: "Order of list (asc/desc)", Flag: "order", Commands: []string{"list"},
+		get: func(c *Config) string { return c.Order },
+		set: func(c *Config, value string) error {
+			if value != "" && value != "asc" && value != "desc" {
+				return fmt.Errorf("%s is not a valid value for order. Allowed values: asc/desc", value)
+			}
+			c.Order = value
+			return nil
+		},
+	},
+	{
+		Name: "limit", Description: "Number of entries listed without --all (1-100)", Flag: "limit", Commands: []string{"list"},
+		get: func(c *Config) string {
+			if c.Limit == 0 {
+				return ""
+			}
+			return strconv.Itoa(c.Limit)
+		},
+		set: func(c *Config, value string) error {
+			if value == "" {
+				c.Limit = 0
+				return nil
+			}
+			limit, err := strconv.Atoi(value)
+			if err != nil || limit < 1 || limit > 100 {
+				return fmt.Errorf("%s is not a valid value for limit. Allowed values: 1-100", value)
+			}
+			c.Limit = limit
+			return nil
+		},
+	},
+	{
+		Name: "cache-ttl", Description: "How long listings are stored on disk and revalidated, e.g. 1h", Flag: "cache-ttl",
+		get: func(c *Config) string { return c.CacheTTL },
+		set: func(c *Config, value string) error {
+			if _, err := time.ParseDuration(value); value != "" && err != nil {
+				return fmt.Errorf("%s is not a valid value for cache-ttl. Expected a duration like 30m or 1h", value)
+			}
+			c.CacheTTL = value
+			return nil
+		},
+	},
+	{
+		Name: "protected-refs", Description: "Refs whose caches are never deleted", Flag: "protect-ref", Cumulative: true,
+		get: func(c *Config) string { return strings.Join(c.ProtectedRefs, ",") },
+		set: func(c *Config, value string) error {
+			c.ProtectedRefs = SplitConfigList(value)
+			return nil
+		},
+	},
+	{
+		Name: "protected-key-prefixes", Description: "Key prefixes of caches that are never deleted", Flag: "protect-key-prefix", Cumulative: true,
+		get: func(c *Config) string { return strings.Join(c.ProtectedKeyPrefixes, ",") },
+		set: func(c *Config, value string) error {
+			c.ProtectedKeyPrefixes = SplitConfigList(value)
+			return nil
+		},
+	},
+	{
+		Name: "max-delete-entries", Description: "Most cache entries deleted at once without --force", Flag: "max-entries",
+		get: func(c *Config) string {
+			if c.MaxDeleteEntries == 0 {
+				return ""
+			}
+			return strconv.Itoa(c.MaxDeleteEntries)
+		},
+		set: func(c *Config, value string) error {
+			if value == "" {
+				c.MaxDeleteEntries = 0
+				return nil
+			}
+			entries, err := strconv.Atoi(value)
+			if err != nil || entries < 1 {
+				return fmt.Errorf("%s is not a valid value for max-delete-entries. It should be at least 1", value)
+			}
+			c.MaxDeleteEntries = entries
+			return nil
+		},
+		limit: func(c *Config) float64 { return float64(c.MaxDeleteEntries) },
+	},
+	{
+		Name: "max-delete-size", Description: "Largest size deleted at once without --force, e.g. 2GB", Flag: "max-size",
+		get: func(c *Config) string { return c.MaxDeleteSize },
+		set: func(c *Config, value string) error {
+			if _, err := ParseByteSize(value); value != "" && err != nil {
+				return fmt.Errorf("%s is not a valid value for max-delete-size. Expected a size like 500MB or 2GB", value)
+			}
+			c.MaxDeleteSize = value
+			return nil
+		},
+		limit: func(c *Config) float64 {
+			size, _ := ParseByteSize(c.MaxDeleteSize)
+			return float64(size)
+		},
+	},
+	{
+		Name: "max-delete-usage-percent", Description: "Largest percentage of the cache usage deleted at once without --force", Flag: "max-usage-percent",
+		get: func(c *Config) string {
+			if c.MaxDeleteUsagePercent == 0 {
+				return ""
+			}
+			return strconv.FormatFloat(c.MaxDeleteUsagePercent, 'g', -1, 64)
+		},
+		set: func(c *Config, value string) error {
+			if value == "" {
+				c.MaxDeleteUsagePercent = 0
+				return nil
+			}
+			percent, err := strconv.ParseFloat(value, 64)
+			if err != nil || percent <= 0 || percent > 100 {
+				return fmt.Errorf("%s is not a valid value for max-delete-usage-percent. Allowed values: 0-100", value)
+			}
+			c.MaxDeleteUsagePercent = percent
+			return nil
+		},
+		limit: func(c *Config) float64 { return c.MaxDeleteUsagePercent },
+	},
+	{
+		Name: "audit-log", Description: "JSON Lines file every deletion is recorded to, not read from the repository file", UserOnly: true,
+		get: func(c *Config) string { return c.AuditLog },
+		set: func(c *Config, value string) error {
+			c.AuditLog = value
+			return nil
+		},
+	},
+}
+
+func FindConfigKey(name string) (ConfigKey, error) {
+	for _, key := range CONFIG_KEYS {
+		if key.Name == name {
+			return key, nil
+		}
+	}
+	names := make([]string, 0, len(CONFIG_KEYS))
+	for _, key := range CONFIG_KEYS {
+		names = append(names, key.Name)
+	}
+	return ConfigKey{}, fmt.Errorf("%s is not a valid configuration key. Allowed keys: %s", name, strings.Join(names, ", "))
+}
+
+// Get returns the value of the setting in c, or an empty string when it is not set.
+func (k ConfigKey) Get(c *Config) string {
+	return k.get(c)
+}
+
+// Set validates value and stores it in c, an empty value unsets the setting.
+func (k ConfigKey) Set(c *Config, value string) error {
+	return k.set(c, strings.TrimSpace(value))
+}
+
+// IsLimit tells whether the setting is a limit, resolved to its lowest value across the configuration layers.
+func (k ConfigKey) IsLimit() bool {
+	return k.limit != nil
+}
+
+// Limit returns the amount of a limit setting in c, the setting being set.
+func (k ConfigKey) Limit(c *Config) float64 {
+	return k.limit(c)
+}
+
+// AppliesTo tells whether the setting replaces the default of a flag of the given command.
+func (k ConfigKey) AppliesTo(command string) bool {
+	return k.Flag != "" && (len(k.Commands) == 0 || contains(k.Commands, command))
+}
+
+// Validate checks every setting of the configuration.
+func (c Config) Validate() error {
+	for _, key := range CONFIG_KEYS {
+		if err := key.Set(&Config{}, key.Get(&c)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SplitConfigList splits a comma separated list, dropping the empty items.
+func SplitConfigList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,59 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigKey_SetAndGet(t *testing.T) {
+	var config Config
+
+	key, err := FindConfigKey("columns")
+	require.NoError(t, err)
+	require.NoError(t, key.Set(&config, " key, size ,id,"))
+	assert.Equal(t, []string{"key", "size", "id"}, config.Columns)
+	assert.Equal(t, "key,size,id", key.Get(&config))
+
+	key, err = FindConfigKey("limit")
+	require.NoError(t, err)
+	require.NoError(t, key.Set(&config, "50"))
+	assert.Equal(t, 50, config.Limit)
+	require.NoError(t, key.Set(&config, ""))
+	assert.Equal(t, "", key.Get(&config))
+}
+
+func TestConfigKey_SetRejectsInvalidValues(t *testing.T) {
+	for name, value := range map[string]string{
+		"repo":        "testRepo",
+		"format":      "xml",
+		"columns":     "key,owner",
+		"time-format": "iso",
+		"sort":        "name",
+		"order":       "up",
+		"limit":       "101",
+		"cache-ttl":   "forever",
+	} {
+		key, err := FindConfigKey(name)
+		require.NoError(t, err)
+		assert.Error(t, key.Set(&Config{}, value), name)
+	}
+}
+
+func TestFindConfigKey_Unknown(t *testing.T) {
+	_, err := FindConfigKey("colour")
+
+	assert.ErrorContains(t, err, "colour is not a valid configuration key. Allowed keys: repo, format, columns")
+}
+
+func TestConfigKey_AppliesTo(t *testing.T) {
+	format, _ := FindConfigKey("format")
+	repo, _ := FindConfigKey("repo")
+	columns, _ := FindConfigKey("columns")
+
+	assert.True(t, format.AppliesTo("list"))
+	assert.False(t, format.AppliesTo("report"))
+	assert.True(t, repo.AppliesTo("delete"))
+	assert.False(t, columns.AppliesTo("list"))
+}
@@ -1,11 +1,89 @@
 package types
 
+import (
+	"context"
+	"errors"
+)
+
+// ErrorKind classifies a HandledError for scripts, every kind exits with its own code.
+type ErrorKind int
+
+const (
+	ErrorKindInternal ErrorKind = iota
+	ErrorKindValidation
+	ErrorKindNotFound
+	ErrorKindForbidden
+	ErrorKindRateLimited
+	ErrorKindPartialFailure
+	ErrorKindCancelled
+	ErrorKindTimeout
+)
+
+// Exit codes of the extension. They are documented in the README and must not change.
+const (
+	EXIT_OK              = 0
+	EXIT_ERROR           = 1
+	EXIT_VALIDATION      = 2
+	EXIT_NOT_FOUND       = 3
+	EXIT_FORBIDDEN       = 4
+	EXIT_RATE_LIMITED    = 5
+	EXIT_PARTIAL_FAILURE = 6
+	EXIT_TIMEOUT         = 7
+	EXIT_CANCELLED       = 130
+)
+
+var exitCodes = map[ErrorKind]int{
+	ErrorKindInternal:       EXIT_ERROR,
+	ErrorKindValidation:     EXIT_VALIDATION,
+	ErrorKindNotFound:       EXIT_NOT_FOUND,
+	ErrorKindForbidden:      EXIT_FORBIDDEN,
+	ErrorKindRateLimited:    EXIT_RATE_LIMITED,
+	ErrorKindPartialFailure: EXIT_PARTIAL_FAILURE,
+	ErrorKindCancelled:      EXIT_CANCELLED,
+	ErrorKindTimeout:        EXIT_TIMEOUT,
+}
+
+func (k ErrorKind) ExitCode() int {
+	if code, ok := exitCodes[k]; ok {
+		return code
+	}
+	return EXIT_ERROR
+}
+
 type HandledError struct {
 	Message    string
 	InnerError error
+	Kind       ErrorKind
 }
 
 // Allow HandledError to satisfy error interface.
 func (err HandledError) Error() string {
 	return err.Message
 }
+
+func (err HandledError) Unwrap() error {
+	return err.InnerError
+}
+
+// ErrInterrupted is returned by long running operations that stopped before starting their next request because
+// the user pressed Ctrl-C.
+var ErrInterrupted = errors.New("interrupted")
+
+// ExitCode returns the exit code of the process for the error returned by a command. Commands return a
+// HandledError for every failure past the validation of their arguments and flags, so other errors are usage
+// errors.
+func ExitCode(err error) int {
+	if err == nil {
+		return EXIT_OK
+	}
+	var handledError HandledError
+	switch {
+	case errors.As(err, &handledError):
+		return handledError.Kind.ExitCode()
+	case errors.Is(err, ErrInterrupted), errors.Is(err, context.Canceled):
+		return EXIT_CANCELLED
+	case errors.Is(err, context.DeadlineExceeded):
+		return EXIT_TIMEOUT
+	}
+	return EXIT_VALIDATION
+}
@@ -0,0 +1,20 @@
+package types
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExitCode(t *testing.T) {
+	assert.Equal(t, EXIT_OK, ExitCode(nil))
+	assert.Equal(t, EXIT_ERROR, ExitCode(HandledError{Message: "We could not process your request due to internal error."}))
+	assert.Equal(t, EXIT_NOT_FOUND, ExitCode(HandledError{Message: "The given repo does not exist.", Kind: ErrorKindNotFound}))
+	assert.Equal(t, EXIT_RATE_LIMITED, ExitCode(fmt.Errorf("listing: %w", HandledError{Kind: ErrorKindRateLimited})))
+	assert.Equal(t, EXIT_CANCELLED, ExitCode(fmt.Errorf("listing: %w", ErrInterrupted)))
+	assert.Equal(t, EXIT_TIMEOUT, ExitCode(context.DeadlineExceeded))
+	assert.Equal(t, EXIT_VALIDATION, ExitCode(errors.New("unknown flag: --foo")))
+}
@@ -0,0 +1,21 @@
+package types
+
+// CacheInventory is a full cache listing deduplicated by id. Entries created or evicted while the pages are
+// fetched shift the following pages, which skips or repeats entries; Drift tells whether that happened.
+type CacheInventory struct {
+	Caches []Cache
+	// TotalCount is the total count reported by the last page.
+	TotalCount int
+	Duplicates int
+	Scans      int
+}
+
+// Drift returns the number of entries reported by the API but missing from the inventory, or the negative number
+// of extra entries.
+func (i CacheInventory) Drift() int {
+	return i.TotalCount - len(i.Caches)
+}
+
+func (i CacheInventory) IsConsistent() bool {
+	return i.Drift() == 0
+}
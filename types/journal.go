@@ -0,0 +1,44 @@
+package types
+
+import "time"
+
+const (
+	JOURNAL_DELETED = "deleted"
+	// JOURNAL_GONE marks the caches that were already deleted or evicted when their deletion was attempted.
+	JOURNAL_GONE = "gone"
+)
+
+// JournalPlan is the first line of a deletion journal, the caches a bulk deletion set out to delete.
+type JournalPlan struct {
+	// Repo is HOST/OWNER/REPO.
+	Repo      string    `json:"repo"`
+	CreatedAt time.Time `json:"created_at"`
+	Reason    string    `json:"reason,omitempty"`
+	Caches    []Cache   `json:"actions_caches"`
+	// Verify is set by apply, whose caches are only deleted while unchanged since the plan, also when resuming.
+	Verify bool `json:"verify,omitempty"`
+}
+
+// JournalRecord is a line of a deletion journal: the plan on the first line, then one line per finished deletion.
+type JournalRecord struct {
+	Plan    *JournalPlan `json:"plan,omitempty"`
+	CacheId int64        `json:"cache_id,omitempty"`
+	Status  string       `json:"status,omitempty"`
+}
+
+// DeletionJournal is the plan of a bulk deletion with the status of the caches whose deletion finished.
+type DeletionJournal struct {
+	Plan      JournalPlan
+	Completed map[int64]string
+}
+
+// Remaining returns the planned caches whose deletion did not finish, in the order of the plan.
+func (j DeletionJournal) Remaining() []Cache {
+	var remaining []Cache
+	for _, cache := range j.Plan.Caches {
+		if _, ok := j.Completed[cache.Id]; !ok {
+			remaining = append(remaining, cache)
+		}
+	}
+	return remaining
+}
@@ -0,0 +1,59 @@
+package types
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var BYTE_SIZE_UNITS = map[string]int64{
+	"":   1,
+	"B":  1,
+	"KB": 1024,
+	"MB": 1024 * 1024,
+	"GB": 1024 * 1024 * 1024,
+}
+
+// DeletionLimits caps what a single invocation deletes. Zero values don't limit anything.
+type DeletionLimits struct {
+	MaxEntries int
+	// MaxSize is a size like 500MB or 2GB.
+	MaxSize         string
+	MaxUsagePercent float64
+	// Force deletes whatever the limits.
+	Force bool
+}
+
+func (l DeletionLimits) Validate() error {
+	if l.MaxEntries < 0 {
+		return fmt.Errorf(fmt.Sprintf("%d is not a valid integer value for max-entries flag. It should be at least 0", l.MaxEntries))
+	}
+	if _, err := ParseByteSize(l.MaxSize); l.MaxSize != "" && err != nil {
+		return fmt.Errorf(fmt.Sprintf("%s is not a valid value for max-size flag. Expected a size like 500MB or 2GB", l.MaxSize))
+	}
+	if l.MaxUsagePercent < 0 || l.MaxUsagePercent > 100 {
+		return fmt.Errorf(fmt.Sprintf("%v is not a valid value for max-usage-percent flag. Allowed values: 0-100", l.MaxUsagePercent))
+	}
+	return nil
+}
+
+// Enabled tells whether any limit applies.
+func (l DeletionLimits) Enabled() bool {
+	return !l.Force && (l.MaxEntries > 0 || l.MaxSize != "" || l.MaxUsagePercent > 0)
+}
+
+// ParseByteSize reads a size in bytes with an optional B, KB, MB or GB unit of 1024 multiples, like the sizes
+// printed by the commands.
+func ParseByteSize(value string) (int64, error) {
+	value = strings.ToUpper(strings.TrimSpace(value))
+	number := strings.TrimRight(value, "KMGB ")
+	multiple, ok := BYTE_SIZE_UNITS[strings.TrimSpace(value[len(number):])]
+	if !ok {
+		return 0, fmt.Errorf("unknown unit in size %s", value)
+	}
+	size, err := strconv.ParseFloat(number, 64)
+	if err != nil || size < 0 {
+		return 0, fmt.Errorf("invalid size %s", value)
+	}
+	return int64(size * float64(multiple)), nil
+}
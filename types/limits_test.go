@@ -0,0 +1,40 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseByteSize(t *testing.T) {
+	for value, expected := range map[string]int64{
+		"512":     512,
+		"512B":    512,
+		"1.5 KB":  1536,
+		"500mb":   500 * 1024 * 1024,
+		"2GB":     2 * 1024 * 1024 * 1024,
+		" 10 GB ": 10 * 1024 * 1024 * 1024,
+	} {
+		size, err := ParseByteSize(value)
+		require.NoError(t, err, value)
+		assert.Equal(t, expected, size, value)
+	}
+
+	for _, value := range []string{"", "GB", "2TB", "-1MB", "two"} {
+		_, err := ParseByteSize(value)
+		assert.Error(t, err, value)
+	}
+}
+
+func TestDeletionLimits_Validate(t *testing.T) {
+	assert.NoError(t, DeletionLimits{MaxEntries: 10, MaxSize: "1GB", MaxUsagePercent: 50}.Validate())
+	assert.EqualError(t, DeletionLimits{MaxSize: "lots"}.Validate(), "lots is not a valid value for max-size flag. Expected a size like 500MB or 2GB")
+	assert.EqualError(t, DeletionLimits{MaxUsagePercent: 150}.Validate(), "150 is not a valid value for max-usage-percent flag. Allowed values: 0-100")
+}
+
+func TestDeletionLimits_Enabled(t *testing.T) {
+	assert.False(t, DeletionLimits{}.Enabled())
+	assert.True(t, DeletionLimits{MaxEntries: 10}.Enabled())
+	assert.False(t, DeletionLimits{MaxEntries: 10, Force: true}.Enabled())
+}
@@ -5,6 +5,7 @@ import (
 	"net/url"
 	"strconv"
 	"strings"
+	"time"
 )
 
 var SORT_INPUT_TO_QUERY_MAP = map[string]string{
@@ -19,16 +20,133 @@ type BaseOptions struct {
 	Key    string
 }
 
+var OUTPUT_FORMATS = []string{"table", "csv", "tsv", "json"}
+
 type ListOptions struct {
 	BaseOptions
-	Limit int
-	Order string
-	Sort  string
+	Limit    int
+	Order    string
+	Sort     string
+	Format   string
+	All      bool
+	Summary  bool
+	Watch    bool
+	Interval time.Duration
+	Stable   bool
 }
 
 type DeleteOptions struct {
 	BaseOptions
-	Confirm bool
+	ProtectionOptions
+	DeletionLimits
+	Confirm  bool
+	Summary  bool
+	Select   bool
+	FromFile string
+	Parallel int
+	Reason   string
+}
+
+const MAX_PARALLEL_DELETIONS = 20
+
+type PruneOptions struct {
+	BaseOptions
+	ProtectionOptions
+	DeletionLimits
+	OlderThan string
+	Confirm   bool
+	Summary   bool
+	Parallel  int
+	Reason    string
+	Journal   string
+	Resume    string
+	Out       string
+}
+
+type ProtectionOptions struct {
+	ProtectRefs        []string
+	ProtectKeyPrefixes []string
+	AllowProtected     bool
+}
+
+// Protection returns the caches to skip, none when protected caches are allowed to be deleted.
+func (o ProtectionOptions) Protection() Protection {
+	if o.AllowProtected {
+		return Protection{}
+	}
+	return Protection{Refs: o.ProtectRefs, KeyPrefixes: o.ProtectKeyPrefixes}
+}
+
+func (o *DeleteOptions) Validate() error {
+	if err := ValidateParallel(o.Parallel); err != nil {
+		return err
+	}
+	return o.DeletionLimits.Validate()
+}
+
+func (o *PruneOptions) Validate() error {
+	if err := ValidateParallel(o.Parallel); err != nil {
+		return err
+	}
+	if err := o.DeletionLimits.Validate(); err != nil {
+		return err
+	}
+	if _, err := ParseAge(o.OlderThan); o.OlderThan != "" && err != nil {
+		return fmt.Errorf(fmt.Sprintf("%s is not a valid value for older-than flag. Expected a duration like 72h or 30d", o.OlderThan))
+	}
+	if o.Resume != "" {
+		if o.Branch != "" || o.Key != "" || o.OlderThan != "" || o.Journal != "" || o.Out != "" {
+			return fmt.Errorf("resume flag cannot be used with the branch, key, older-than, journal and out flags")
+		}
+		return nil
+	}
+	if o.Branch == "" && o.Key == "" && o.OlderThan == "" {
+		return fmt.Errorf("at least one of the branch, key and older-than flags is required, or resume")
+	}
+	if o.Out != "" && o.Journal != "" {
+		return fmt.Errorf("out flag writes a plan without deleting and cannot be used with the journal flag")
+	}
+	return nil
+}
+
+type ApplyOptions struct {
+	ProtectionOptions
+	DeletionLimits
+	Confirm  bool
+	Summary  bool
+	Parallel int
+	Reason   string
+	Journal  string
+}
+
+func (o *ApplyOptions) Validate() error {
+	if err := ValidateParallel(o.Parallel); err != nil {
+		return err
+	}
+	return o.DeletionLimits.Validate()
+}
+
+// ParseAge reads a duration like 72h, or a number of days like 30d.
+func ParseAge(value string) (time.Duration, error) {
+	if days := strings.TrimSuffix(value, "d"); days != value {
+		count, err := strconv.Atoi(days)
+		if err != nil || count < 0 {
+			return 0, fmt.Errorf("invalid number of days %s", value)
+		}
+		return time.Duration(count) * 24 * time.Hour, nil
+	}
+	age, err := time.ParseDuration(value)
+	if err == nil && age < 0 {
+		return 0, fmt.Errorf("negative duration %s", value)
+	}
+	return age, err
+}
+
+func ValidateParallel(parallel int) error {
+	if parallel < 1 || parallel > MAX_PARALLEL_DELETIONS {
+		return fmt.Errorf(fmt.Sprintf("%d is not a valid integer value for parallel flag. Allowed values: 1-%d", parallel, MAX_PARALLEL_DELETIONS))
+	}
+	return nil
 }
 
 func (o *ListOptions) Validate() error {
@@ -44,9 +162,38 @@ func (o *ListOptions) Validate() error {
 		return fmt.Errorf(fmt.Sprintf("%d is not a valid integer value for limit flag. Allowed values: 1-100", o.Limit))
 	}
 
+	if o.Format != "" && !isValidOutputFormat(o.Format) {
+		return fmt.Errorf(fmt.Sprintf("%s is not a valid value for format flag. Allowed values: %s", o.Format, strings.Join(OUTPUT_FORMATS, "/")))
+	}
+
+	if o.Watch && o.Interval < time.Second {
+		return fmt.Errorf(fmt.Sprintf("%s is not a valid value for interval flag. It should be at least 1s", o.Interval))
+	}
+
+	if o.Watch && o.Format != "" && o.Format != "table" {
+		return fmt.Errorf("watch flag can only be used with the table format")
+	}
+
+	if o.Stable && !o.All {
+		return fmt.Errorf("stable flag can only be used with the all flag")
+	}
+
+	if o.Stable && (o.Sort != "" || o.Order != "") {
+		return fmt.Errorf("stable flag lists caches by creation date and cannot be used with the sort and order flags")
+	}
+
 	return nil
 }
 
+func isValidOutputFormat(format string) bool {
+	for _, f := range OUTPUT_FORMATS {
+		if f == format {
+			return true
+		}
+	}
+	return false
+}
+
 func (o *BaseOptions) GenerateBaseQueryParams(query url.Values) {
 	if o.Branch != "" {
 		if strings.HasPrefix(o.Branch, "refs/") {
@@ -62,7 +209,9 @@ func (o *BaseOptions) GenerateBaseQueryParams(query url.Values) {
 }
 
 func (o *ListOptions) GenerateQueryParams(query url.Values) {
-	if o.Limit != 30 {
+	if o.All {
+		query.Add("per_page", "100")
+	} else if o.Limit != 30 {
 		query.Add("per_page", strconv.Itoa(o.Limit))
 	}
 
@@ -76,3 +225,27 @@ func (o *ListOptions) GenerateQueryParams(query url.Values) {
 
 	o.GenerateBaseQueryParams(query)
 }
+
+type ReportOptions struct {
+	Repo    string
+	Format  string
+	Output  string
+	Top     int
+	QuotaGB int
+}
+
+func (o *ReportOptions) Validate() error {
+	if o.Format != "markdown" && o.Format != "html" {
+		return fmt.Errorf(fmt.Sprintf("%s is not a valid value for format flag. Allowed values: markdown/html", o.Format))
+	}
+
+	if o.Top < 1 {
+		return fmt.Errorf(fmt.Sprintf("%d is not a valid integer value for top flag. It should be greater than 0", o.Top))
+	}
+
+	if o.QuotaGB < 1 {
+		return fmt.Errorf(fmt.Sprintf("%d is not a valid integer value for quota flag. It should be greater than 0", o.QuotaGB))
+	}
+
+	return nil
+}
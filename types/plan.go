@@ -0,0 +1,33 @@
+package types
+
+import "time"
+
+// CleanupPlan is the list of caches selected for deletion by prune --out, to be reviewed and deleted later by apply.
+type CleanupPlan struct {
+	// Repo is HOST/OWNER/REPO.
+	Repo      string      `json:"repo"`
+	CreatedAt time.Time   `json:"created_at"`
+	Reason    string      `json:"reason,omitempty"`
+	Entries   []PlanEntry `json:"entries"`
+}
+
+// PlanEntry is a cache as it was when planned. Apply only deletes it if it is still the same.
+type PlanEntry struct {
+	Id             int64     `json:"id"`
+	Key            string    `json:"key"`
+	Ref            string    `json:"ref"`
+	Version        string    `json:"version"`
+	SizeInBytes    int64     `json:"size_in_bytes"`
+	LastAccessedAt time.Time `json:"last_accessed_at"`
+}
+
+func NewPlanEntry(cache Cache) PlanEntry {
+	return PlanEntry{
+		Id:             cache.Id,
+		Key:            cache.Key,
+		Ref:            cache.Ref.Raw,
+		Version:        cache.Version,
+		SizeInBytes:    cache.SizeInBytes,
+		LastAccessedAt: cache.LastAccessedAt,
+	}
+}
@@ -0,0 +1,48 @@
+package types
+
+import "strings"
+
+// Protection lists the caches that deletions must skip.
+type Protection struct {
+	// Refs are full refs like refs/pull/2/merge or branch names like main.
+	Refs        []string
+	KeyPrefixes []string
+}
+
+func (p Protection) IsEmpty() bool {
+	return len(p.Refs) == 0 && len(p.KeyPrefixes) == 0
+}
+
+// Protects tells whether cache belongs to a protected ref or has a protected key prefix.
+func (p Protection) Protects(cache Cache) bool {
+	for _, ref := range p.Refs {
+		if !strings.HasPrefix(ref, "refs/") {
+			ref = "refs/heads/" + ref
+		}
+		if cache.Ref.Raw == ref {
+			return true
+		}
+	}
+	for _, prefix := range p.KeyPrefixes {
+		if strings.HasPrefix(cache.Key, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// Split separates the caches to delete from the protected ones.
+func (p Protection) Split(caches []Cache) ([]Cache, []Cache) {
+	if p.IsEmpty() {
+		return caches, nil
+	}
+	var unprotected, protected []Cache
+	for _, cache := range caches {
+		if p.Protects(cache) {
+			protected = append(protected, cache)
+		} else {
+			unprotected = append(unprotected, cache)
+		}
+	}
+	return unprotected, protected
+}
@@ -0,0 +1,32 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProtection_Split(t *testing.T) {
+	main := Cache{Id: 1, Key: "toolchain-linux", Ref: ParseRef("refs/heads/main")}
+	pullRequest := Cache{Id: 2, Key: "node-modules", Ref: ParseRef("refs/pull/2/merge")}
+	release := Cache{Id: 3, Key: "node-modules", Ref: ParseRef("refs/tags/v1.0")}
+	caches := []Cache{main, pullRequest, release}
+
+	unprotected, protected := Protection{Refs: []string{"main", "refs/tags/v1.0"}}.Split(caches)
+	assert.Equal(t, []Cache{pullRequest}, unprotected)
+	assert.Equal(t, []Cache{main, release}, protected)
+
+	unprotected, protected = Protection{KeyPrefixes: []string{"toolchain-"}}.Split(caches)
+	assert.Equal(t, []Cache{pullRequest, release}, unprotected)
+	assert.Equal(t, []Cache{main}, protected)
+
+	unprotected, protected = Protection{}.Split(caches)
+	assert.Equal(t, caches, unprotected)
+	assert.Empty(t, protected)
+}
+
+func TestProtectionOptions_AllowProtected(t *testing.T) {
+	options := ProtectionOptions{ProtectRefs: []string{"main"}, AllowProtected: true}
+
+	assert.True(t, options.Protection().IsEmpty())
+}
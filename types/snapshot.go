@@ -0,0 +1,10 @@
+package types
+
+import "time"
+
+type CacheSnapshot struct {
+	Repo       string    `json:"repo"`
+	TakenAt    time.Time `json:"taken_at"`
+	TotalCount int       `json:"total_count"`
+	Caches     []Cache   `json:"actions_caches"`
+}